@@ -1,92 +1,49 @@
 package app
 
 import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"os"
-	"time"
-
-	"github.com/spf13/viper"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
 	"github.com/zkMeLabs/mechain-relayer/assembler"
 	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/db"
 	"github.com/zkMeLabs/mechain-relayer/db/dao"
-	"github.com/zkMeLabs/mechain-relayer/db/model"
 	"github.com/zkMeLabs/mechain-relayer/executor"
 	"github.com/zkMeLabs/mechain-relayer/listener"
 	"github.com/zkMeLabs/mechain-relayer/metric"
 	"github.com/zkMeLabs/mechain-relayer/relayer"
 	"github.com/zkMeLabs/mechain-relayer/vote"
+	"github.com/zkMeLabs/mechain-relayer/vote/evidence"
+	"github.com/zkMeLabs/mechain-relayer/vote/protection"
 )
 
 type App struct {
 	BSCRelayer    *relayer.BSCRelayer
 	GnfdRelayer   *relayer.GreenfieldRelayer
 	metricService *metric.MetricService
+	keyRotator    *KeyRotator
+	config        *config.Config
 }
 
-func NewApp(cfg *config.Config) *App {
-	username := viper.GetString(config.FlagConfigDbUsername)
-	if username == "" {
-		username = os.Getenv(config.ConfigDBUserName)
-		if username == "" {
-			username = cfg.DBConfig.Username
-		}
-	}
-	password := viper.GetString(config.FlagConfigDbPass)
-	if password == "" {
-		password = os.Getenv(config.ConfigDBPass)
-		if password == "" {
-			password = getDBPass(&cfg.DBConfig)
-		}
-	}
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-			SlowThreshold:             time.Second,   // Slow SQL threshold
-			LogLevel:                  logger.Silent, // Log level
-			IgnoreRecordNotFoundError: true,          // Ignore ErrRecordNotFound error for logger
-			Colorful:                  true,          // Disable color
-		},
-	)
-	var db *gorm.DB
-	var err error
-	var dialector gorm.Dialector
-
-	if cfg.DBConfig.Dialect == config.DBDialectMysql {
-		url := cfg.DBConfig.Url
-		dbPath := fmt.Sprintf("%s:%s@%s", username, password, url)
-		dialector = mysql.Open(dbPath)
-	} else {
-		panic(fmt.Sprintf("unexpected DB dialect %s", cfg.DBConfig.Dialect))
-	}
-	db, err = gorm.Open(dialector, &gorm.Config{
-		Logger: newLogger,
-	})
+// OpenDB opens the relayer DB.
+func OpenDB(cfg *config.Config) (*gorm.DB, error) {
+	return db.OpenDB(&cfg.DBConfig, cfg.DBConfig.Username, getDBPass(cfg))
+}
+
+// NewApp creates the relayer, the keys are rotated with the keys of the config returned by loadConfig.
+func NewApp(cfg *config.Config, loadConfig ConfigLoader) *App {
+	relayerDB, err := OpenDB(cfg)
 	if err != nil {
-		panic(fmt.Sprintf("open db error, err=%s", err.Error()))
+		panic(err)
 	}
-	dbConfig, err := db.DB()
-	if err != nil {
+	if err = migrateOnStartup(&cfg.DBConfig, relayerDB); err != nil {
 		panic(err)
 	}
 
-	dbConfig.SetMaxIdleConns(cfg.DBConfig.MaxIdleConns)
-	dbConfig.SetMaxOpenConns(cfg.DBConfig.MaxOpenConns)
-
-	model.InitBSCTables(db)
-	model.InitGreenfieldTables(db)
-	model.InitVoteTables(db)
-
 	metricService := metric.NewMetricService(cfg)
 
-	greenfieldDao := dao.NewGreenfieldDao(db)
-	bscDao := dao.NewBSCDao(db)
-	voteDao := dao.NewVoteDao(db)
+	greenfieldDao := dao.NewGreenfieldDao(relayerDB)
+	bscDao := dao.NewBSCDao(relayerDB)
+	voteDao := dao.NewVoteDao(relayerDB)
 	daoManager := dao.NewDaoManager(greenfieldDao, bscDao, voteDao)
 
 	greenfieldExecutor := executor.NewGreenfieldExecutor(cfg)
@@ -96,11 +53,16 @@ func NewApp(cfg *config.Config) *App {
 	bscExecutor.SetGreenfieldExecutor(greenfieldExecutor)
 
 	// vote signer
-	signer := vote.NewVoteSigner(greenfieldExecutor.BlsPrivateKey)
+	journal, err := protection.OpenJournal(cfg.VotePoolConfig.GetSlashingProtectionDBPath())
+	if err != nil {
+		panic(err)
+	}
+	signer := vote.NewVoteSigner(greenfieldExecutor.GetBlsPrivateKey(), journal)
 
 	// voteProcessors
-	greenfieldVoteProcessor := vote.NewGreenfieldVoteProcessor(cfg, daoManager, signer, greenfieldExecutor)
-	bscVoteProcessor := vote.NewBSCVoteProcessor(cfg, daoManager, signer, bscExecutor)
+	evidenceRecorder := evidence.NewRecorder(cfg, daoManager, metricService)
+	greenfieldVoteProcessor := vote.NewGreenfieldVoteProcessor(cfg, daoManager, signer, greenfieldExecutor, evidenceRecorder)
+	bscVoteProcessor := vote.NewBSCVoteProcessor(cfg, daoManager, signer, bscExecutor, evidenceRecorder)
 
 	// listeners
 	greenfieldListener := listener.NewGreenfieldListener(cfg, greenfieldExecutor, bscExecutor, daoManager, metricService)
@@ -114,34 +76,44 @@ func NewApp(cfg *config.Config) *App {
 	gnfdRelayer := relayer.NewGreenfieldRelayer(greenfieldListener, greenfieldExecutor, bscExecutor, greenfieldVoteProcessor, greenfieldAssembler)
 	bscRelayer := relayer.NewBSCRelayer(bscListener, greenfieldExecutor, bscExecutor, bscVoteProcessor, bscAssembler)
 
+	keyRotator := NewKeyRotator(loadConfig, greenfieldExecutor, bscExecutor, signer, bscAssembler, daoManager)
+
 	return &App{
 		BSCRelayer:    bscRelayer,
 		GnfdRelayer:   gnfdRelayer,
 		metricService: metricService,
+		keyRotator:    keyRotator,
+		config:        cfg,
 	}
 }
 
 func (a *App) Start() {
 	a.GnfdRelayer.Start()
 	a.BSCRelayer.Start()
-	a.metricService.Start()
-}
-
-func getDBPass(cfg *config.DBConfig) string {
-	if cfg.KeyType == config.KeyTypeAWSPrivateKey {
-		result, err := config.GetSecret(cfg.AWSSecretName, cfg.AWSRegion)
-		if err != nil {
-			panic(err)
-		}
-		type DBPass struct {
-			DbPass string `json:"db_pass"`
-		}
-		var dbPassword DBPass
-		err = json.Unmarshal([]byte(result), &dbPassword)
+	if a.config.AdminConfig.KeyRotationEnabled {
+		token, err := ReadKeyRotationToken(a.config.AdminConfig.KeyRotationTokenFile)
 		if err != nil {
 			panic(err)
 		}
-		return dbPassword.DbPass
+		go func() {
+			if err := a.keyRotator.ListenAndServe(a.config.AdminConfig.GetKeyRotationAddr(), token); err != nil {
+				panic(err)
+			}
+		}()
+	}
+	if a.config.AdminConfig.WatchKeyFiles {
+		go a.keyRotator.WatchKeyFiles(a.config.KeyFiles())
+	}
+	a.metricService.Start()
+}
+
+// getDBPass returns the password read from the secret provider of the key type, or the configured password for the
+// other key types.
+func getDBPass(cfg *config.Config) string {
+	dbCfg := &cfg.DBConfig
+	password, err := cfg.ResolveSecret(dbCfg.KeyType, dbCfg.AWSRegion, dbCfg.GetSecretName(), config.SecretFieldDBPass, dbCfg.Password)
+	if err != nil {
+		panic(err)
 	}
-	return cfg.Password
+	return password
 }
@@ -0,0 +1,295 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/zkMeLabs/mechain-relayer/assembler"
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/db/dao"
+	"github.com/zkMeLabs/mechain-relayer/executor"
+	"github.com/zkMeLabs/mechain-relayer/logging"
+	"github.com/zkMeLabs/mechain-relayer/vote"
+)
+
+const (
+	// KeyRotationPath is the admin endpoint which rotates the relayer keys
+	KeyRotationPath = "/admin/keys/rotate"
+
+	keyRotationDrainTimeout = 2 * time.Minute
+	keyFileDebounce         = 2 * time.Second
+	// kubernetesSecretDataDir is swapped by a rename when a mounted Kubernetes secret is updated
+	kubernetesSecretDataDir = "..data"
+)
+
+// ErrKeysInconsistent is returned when the vote signer could not be switched back after the Mechain keys were refused,
+// so the vote signer and the Mechain executor hold different BLS keys. Relaying stays paused until a rotation succeeds.
+var ErrKeysInconsistent = errors.New("the vote signer and the Mechain executor hold different keys")
+
+// ConfigLoader reloads the config, the rotated keys are read from its key sources.
+type ConfigLoader func() (*config.Config, error)
+
+// KeyRotator swaps the relayer keys without restarting the relayer. Every path signing with the relayer keys is paused
+// and the transactions sent with the old keys are drained before the keys of the executors and the vote signer are
+// swapped.
+type KeyRotator struct {
+	mutex              sync.Mutex
+	loadConfig         ConfigLoader
+	greenfieldExecutor *executor.GreenfieldExecutor
+	bscExecutor        *executor.BSCExecutor
+	signer             *vote.VoteSigner
+	bscAssembler       *assembler.BSCAssembler
+	daoManager         *dao.DaoManager
+	inconsistent       bool // the keys in use are inconsistent and relaying is kept paused, see ErrKeysInconsistent
+}
+
+func NewKeyRotator(loadConfig ConfigLoader, greenfieldExecutor *executor.GreenfieldExecutor, bscExecutor *executor.BSCExecutor,
+	signer *vote.VoteSigner, bscAssembler *assembler.BSCAssembler, daoManager *dao.DaoManager,
+) *KeyRotator {
+	return &KeyRotator{
+		loadConfig:         loadConfig,
+		greenfieldExecutor: greenfieldExecutor,
+		bscExecutor:        bscExecutor,
+		signer:             signer,
+		bscAssembler:       bscAssembler,
+		daoManager:         daoManager,
+	}
+}
+
+// CurrentKeys returns the identities of the keys in use.
+func (r *KeyRotator) CurrentKeys() *executor.RelayerKeys {
+	return &executor.RelayerKeys{
+		GreenfieldAddress: r.greenfieldExecutor.GetAddress(),
+		BSCAddress:        r.bscExecutor.GetAddress(),
+		BlsPubKey:         r.greenfieldExecutor.GetBlsPubKey(),
+	}
+}
+
+// Rotate reloads the keys from the config and swaps them in if they changed. The old keys stay in use if the new keys
+// can not be loaded or the pending transactions of the old keys are not included in time. If the keys in use are left
+// inconsistent, ErrKeysInconsistent is returned and relaying stays paused until a later rotation sets all the keys.
+func (r *KeyRotator) Rotate() (*executor.RelayerKeys, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cfg, err := r.loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload config, err=%s", err.Error())
+	}
+	keySet, err := executor.LoadRelayerKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	newKeys, err := keySet.RelayerKeys()
+	if err != nil {
+		return nil, err
+	}
+	oldKeys := r.CurrentKeys()
+	if newKeys.Equal(oldKeys) && !r.inconsistent {
+		logging.Logger.Infof("relayer keys are unchanged")
+		return newKeys, nil
+	}
+
+	logging.Logger.Infof("rotating relayer keys, waiting for the signing paths to finish")
+	// relaying is already paused if a previous rotation left the keys inconsistent, and stays paused while they are
+	if !r.inconsistent {
+		r.greenfieldExecutor.PauseRelay()
+	}
+	defer func() {
+		if !r.inconsistent {
+			r.greenfieldExecutor.ResumeRelay()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyRotationDrainTimeout)
+	defer cancel()
+	if err = r.bscExecutor.WaitForPendingTxs(ctx); err != nil {
+		return nil, fmt.Errorf("failed to drain the BSC transactions of the old key, keys are not rotated, err=%s", err.Error())
+	}
+	if err = r.greenfieldExecutor.WaitForPendingTxs(ctx); err != nil {
+		return nil, fmt.Errorf("failed to drain the Mechain transactions of the old key, keys are not rotated, err=%s", err.Error())
+	}
+
+	if err = setGreenfieldKeys(r.signer, r.greenfieldExecutor, keySet.GreenfieldPrivateKey, keySet.BlsPrivateKey); err != nil {
+		if errors.Is(err, ErrKeysInconsistent) {
+			r.inconsistent = true
+			logging.Logger.Errorf("relaying is paused until the keys are rotated again, err=%s", err.Error())
+		}
+		return nil, err
+	}
+	r.inconsistent = false
+	r.bscExecutor.SetSigner(keySet.BSCSigner)
+	r.bscAssembler.ResetNonce()
+
+	if !bytes.Equal(newKeys.BlsPubKey, oldKeys.BlsPubKey) {
+		// the votes of the old BLS key can not be completed by the new one, vote the pending events again
+		if _, err = r.daoManager.GreenfieldDao.ResetSelfVotedTransactions(); err != nil {
+			logging.Logger.Errorf("failed to reset self voted transactions, err=%s", err.Error())
+		}
+		if _, err = r.daoManager.BSCDao.ResetSelfVotedPackages(); err != nil {
+			logging.Logger.Errorf("failed to reset self voted packages, err=%s", err.Error())
+		}
+	}
+	logging.Logger.Infof("rotated relayer keys, Mechain address %s -> %s, BSC address %s -> %s, BLS public key %s -> %s",
+		oldKeys.GreenfieldAddress, newKeys.GreenfieldAddress, oldKeys.BSCAddress.Hex(), newKeys.BSCAddress.Hex(),
+		hex.EncodeToString(oldKeys.BlsPubKey), hex.EncodeToString(newKeys.BlsPubKey))
+	return newKeys, nil
+}
+
+// blsKeySetter is the vote signer, whose BLS key is swapped with the Mechain keys.
+type blsKeySetter interface {
+	SetKey(blsPrivKey []byte) error
+}
+
+// greenfieldKeySetter is the Mechain executor, whose keys are swapped.
+type greenfieldKeySetter interface {
+	SetKeys(privKey string, blsPrivKey []byte) error
+	GetBlsPrivateKey() []byte
+}
+
+// setGreenfieldKeys swaps the BLS key of the vote signer and the keys of the Mechain executor. If the executor refuses
+// its keys the vote signer is switched back to the BLS key of the executor, ErrKeysInconsistent is returned if that
+// fails too.
+func setGreenfieldKeys(signer blsKeySetter, greenfieldExecutor greenfieldKeySetter, privKey string, blsPrivKey []byte) error {
+	if err := signer.SetKey(blsPrivKey); err != nil {
+		return fmt.Errorf("failed to set bls key of the vote signer, keys are not rotated, err=%s", err.Error())
+	}
+	if err := greenfieldExecutor.SetKeys(privKey, blsPrivKey); err != nil {
+		if e := signer.SetKey(greenfieldExecutor.GetBlsPrivateKey()); e != nil {
+			return fmt.Errorf("%w, failed to set Mechain keys, err=%s, and to switch the vote signer back, err=%s",
+				ErrKeysInconsistent, err.Error(), e.Error())
+		}
+		return fmt.Errorf("failed to set Mechain keys, keys are not rotated, err=%s", err.Error())
+	}
+	return nil
+}
+
+type rotateKeysResponse struct {
+	GreenfieldAddress string `json:"mechain_address"`
+	BSCAddress        string `json:"bsc_address"`
+	BlsPubKey         string `json:"bls_public_key"`
+}
+
+// ReadKeyRotationToken reads the bearer token required by the key rotation endpoint from the token file.
+func ReadKeyRotationToken(tokenFile string) (string, error) {
+	bz, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key rotation token file %s, err=%s", tokenFile, err.Error())
+	}
+	token := strings.TrimSpace(string(bz))
+	if token == "" {
+		return "", fmt.Errorf("key rotation token file %s is empty", tokenFile)
+	}
+	return token, nil
+}
+
+// ListenAndServe serves the key rotation endpoint on its own listener, apart from the metrics. Requests have to carry
+// the token as bearer token.
+func (r *KeyRotator) ListenAndServe(addr, token string) error {
+	mux := http.NewServeMux()
+	mux.Handle(KeyRotationPath, requireBearerToken(token, r))
+	logging.Logger.Infof("serving key rotation on %s", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), expected) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// ServeHTTP rotates the keys on a POST request and responds with the identities of the keys in use.
+func (r *KeyRotator) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	keys, err := r.Rotate()
+	if err != nil {
+		logging.Logger.Errorf("failed to rotate relayer keys, err=%s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(&rotateKeysResponse{
+		GreenfieldAddress: keys.GreenfieldAddress,
+		BSCAddress:        keys.BSCAddress.Hex(),
+		BlsPubKey:         hex.EncodeToString(keys.BlsPubKey),
+	})
+}
+
+// WatchKeyFiles rotates the keys when one of the files changes. The parent directories are watched, so files replaced
+// by a rename, as editors and Kubernetes secret volumes do, are noticed too. Changes are merged for keyFileDebounce.
+func (r *KeyRotator) WatchKeyFiles(files []string) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logging.Logger.Errorf("failed to watch key files, err=%s", err.Error())
+		return
+	}
+	defer watcher.Close()
+
+	watched := make(map[string]struct{})
+	dirs := make(map[string]struct{})
+	for _, f := range files {
+		path, err := filepath.Abs(f)
+		if err != nil {
+			logging.Logger.Errorf("failed to watch key file %s, err=%s", f, err.Error())
+			continue
+		}
+		watched[path] = struct{}{}
+		dir := filepath.Dir(path)
+		if _, ok := dirs[dir]; ok {
+			continue
+		}
+		if err = watcher.Add(dir); err != nil {
+			logging.Logger.Errorf("failed to watch key file directory %s, err=%s", dir, err.Error())
+			continue
+		}
+		dirs[dir] = struct{}{}
+	}
+	logging.Logger.Infof("watching key files %v", files)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			_, isKeyFile := watched[filepath.Clean(event.Name)]
+			if !isKeyFile && filepath.Base(event.Name) != kubernetesSecretDataDir {
+				continue
+			}
+			logging.Logger.Infof("key file %s changed, op=%s", event.Name, event.Op.String())
+			debounce = time.After(keyFileDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logging.Logger.Errorf("key file watcher error, err=%s", err.Error())
+		case <-debounce:
+			debounce = nil
+			if _, err := r.Rotate(); err != nil {
+				logging.Logger.Errorf("failed to rotate relayer keys, err=%s", err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBlsKeySetter struct {
+	key  []byte
+	errs []error // returned by the next calls of SetKey
+}
+
+func (f *fakeBlsKeySetter) SetKey(blsPrivKey []byte) error {
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	f.key = blsPrivKey
+	return nil
+}
+
+type fakeGreenfieldKeySetter struct {
+	privKey    string
+	blsPrivKey []byte
+	err        error
+}
+
+func (f *fakeGreenfieldKeySetter) SetKeys(privKey string, blsPrivKey []byte) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.privKey, f.blsPrivKey = privKey, blsPrivKey
+	return nil
+}
+
+func (f *fakeGreenfieldKeySetter) GetBlsPrivateKey() []byte {
+	return f.blsPrivKey
+}
+
+func TestSetGreenfieldKeys(t *testing.T) {
+	oldBlsKey, newBlsKey := []byte("old bls key"), []byte("new bls key")
+	for _, tc := range []struct {
+		name         string
+		signerErrs   []error
+		executorErr  error
+		wantErr      bool
+		inconsistent bool
+		signerKey    []byte
+		executorKey  []byte
+	}{
+		{
+			name:        "both keys are swapped",
+			signerKey:   newBlsKey,
+			executorKey: newBlsKey,
+		},
+		{
+			name:        "the signer refuses its key",
+			signerErrs:  []error{errors.New("invalid key")},
+			wantErr:     true,
+			signerKey:   oldBlsKey,
+			executorKey: oldBlsKey,
+		},
+		{
+			name:        "the signer is switched back when the executor refuses its keys",
+			executorErr: errors.New("invalid key"),
+			wantErr:     true,
+			signerKey:   oldBlsKey,
+			executorKey: oldBlsKey,
+		},
+		{
+			name:         "the keys are inconsistent when the signer can not be switched back",
+			signerErrs:   []error{nil, errors.New("signer failure")},
+			executorErr:  errors.New("invalid key"),
+			wantErr:      true,
+			inconsistent: true,
+			signerKey:    newBlsKey,
+			executorKey:  oldBlsKey,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			signer := &fakeBlsKeySetter{key: oldBlsKey, errs: tc.signerErrs}
+			greenfieldExecutor := &fakeGreenfieldKeySetter{privKey: "old", blsPrivKey: oldBlsKey, err: tc.executorErr}
+
+			err := setGreenfieldKeys(signer, greenfieldExecutor, "new", newBlsKey)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.inconsistent, errors.Is(err, ErrKeysInconsistent))
+			require.Equal(t, tc.signerKey, signer.key)
+			require.Equal(t, tc.executorKey, greenfieldExecutor.blsPrivKey)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/db/migration"
+	"github.com/zkMeLabs/mechain-relayer/logging"
+)
+
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+)
+
+func migrateOnStartup(cfg *config.DBConfig, relayerDB *gorm.DB) error {
+	migrator := migration.NewMigrator(relayerDB)
+	if cfg.SkipMigration {
+		pending, err := migrator.HasPending()
+		if err != nil {
+			return err
+		}
+		if pending {
+			return fmt.Errorf("db schema is not up to date, run the migrate up subcommand or enable migration on startup")
+		}
+		return nil
+	}
+	applied, err := migrator.Up()
+	if err != nil {
+		return err
+	}
+	for _, m := range applied {
+		logging.Logger.Infof("applied db migration %d %s", m.Version, m.Name)
+	}
+	return nil
+}
+
+// Migrate runs a migrate subcommand against the relayer DB. up applies all pending migrations, down reverts the latest
+// steps migrations and status prints every migration with its state.
+func Migrate(cfg *config.Config, command string, steps int) error {
+	relayerDB, err := OpenDB(cfg)
+	if err != nil {
+		return err
+	}
+	migrator := migration.NewMigrator(relayerDB)
+	switch command {
+	case MigrateUp:
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied %d %s\n", m.Version, m.Name)
+		}
+		return err
+	case MigrateDown:
+		if steps <= 0 {
+			return fmt.Errorf("steps should be positive, steps=%d", steps)
+		}
+		reverted, err := migrator.Down(steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %d %s\n", m.Version, m.Name)
+		}
+		return err
+	case MigrateStatus:
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = fmt.Sprintf("applied at %s", time.Unix(s.AppliedTime, 0).UTC().Format(time.RFC3339))
+			}
+			fmt.Printf("%d %s: %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %s", command)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -72,9 +73,9 @@ type BSCAssembler struct {
 	greenfieldExecutor          *executor.GreenfieldExecutor
 	bscExecutor                 *executor.BSCExecutor
 	daoManager                  *dao.DaoManager
-	blsPubKey                   []byte
 	inturnRelayerSequenceStatus *types.SequenceStatus
 	relayerNonce                uint64
+	resync                      atomic.Bool // the in-turn relayer reads its sequence and nonce from chain in the next round
 	metricService               *metric.MetricService
 	alertSet                    map[uint64]struct{}
 }
@@ -85,7 +86,6 @@ func NewBSCAssembler(cfg *config.Config, executor *executor.BSCExecutor, dao *da
 		bscExecutor:                 executor,
 		daoManager:                  dao,
 		greenfieldExecutor:          greenfieldExecutor,
-		blsPubKey:                   greenfieldExecutor.BlsPubKey,
 		inturnRelayerSequenceStatus: &types.SequenceStatus{},
 		metricService:               ms,
 		alertSet:                    make(map[uint64]struct{}, 0),
@@ -100,12 +100,20 @@ func (a *BSCAssembler) AssemblePackagesAndClaimLoop() {
 func (a *BSCAssembler) assemblePackagesAndClaimForOracleChannel(channelId types.ChannelId) {
 	ticker := time.NewTicker(common.AssembleInterval)
 	for range ticker.C {
-		if err := a.process(channelId); err != nil {
+		a.greenfieldExecutor.BeginRelay()
+		err := a.process(channelId)
+		a.greenfieldExecutor.EndRelay()
+		if err != nil {
 			logging.Logger.Errorf("encounter error, err=%s ", err.Error())
 		}
 	}
 }
 
+// ResetNonce makes the next relay round read the nonce from chain, it is called after the keys are rotated.
+func (a *BSCAssembler) ResetNonce() {
+	a.resync.Store(true)
+}
+
 func (a *BSCAssembler) process(channelId types.ChannelId) error {
 	claimSrcChain := oracletypes.CLAIM_SRC_CHAIN_UNSPECIFIED
 	// if a.config.BSCConfig.IsOpCrossChain() {
@@ -137,7 +145,7 @@ func (a *BSCAssembler) process(channelId types.ChannelId) error {
 	if err != nil {
 		return fmt.Errorf("failed to decode inturn relayer bls pub key, err=%s", err.Error())
 	}
-	isInturnRelyer := bytes.Equal(a.blsPubKey, inturnRelayerPubkey)
+	isInturnRelyer := bytes.Equal(a.greenfieldExecutor.GetBlsPubKey(), inturnRelayerPubkey)
 	a.metricService.SetGnfdInturnRelayerMetrics(isInturnRelyer, inturnRelayer.RelayInterval.Start, inturnRelayer.RelayInterval.End)
 
 	var (
@@ -145,6 +153,9 @@ func (a *BSCAssembler) process(channelId types.ChannelId) error {
 		endSequence int64
 	)
 
+	if a.resync.Swap(false) {
+		a.inturnRelayerSequenceStatus.HasRetrieved = false
+	}
 	if isInturnRelyer {
 		// GetNextDeliveryOracleSequenceWithRetry, _ := a.bscExecutor.GetNextDeliveryOracleSequenceWithRetry(a.getChainId())
 		// logging.Logger.Debugf("a.inturnRelayerSequenceStatus.NextDeliverySeq %d, HasRetrieved %t, GetNextDeliveryOracleSequenceWithRetry %d", a.inturnRelayerSequenceStatus.NextDeliverySeq, a.inturnRelayerSequenceStatus.HasRetrieved, GetNextDeliveryOracleSequenceWithRetry)
@@ -191,7 +202,7 @@ func (a *BSCAssembler) process(channelId types.ChannelId) error {
 		return err
 	}
 	if isInturnRelyer {
-		endSequence, err = a.daoManager.BSCDao.GetLatestOracleSequenceByStatus(db.AllVoted)
+		endSequence, err = a.daoManager.BSCDao.GetLatestOracleSequenceByStatus(db.AllVoted, db.Failed)
 		if err != nil {
 			return fmt.Errorf("faield to get latest oracle sequence from DB, err=%s", err.Error())
 		}
@@ -221,10 +232,13 @@ func (a *BSCAssembler) process(channelId types.ChannelId) error {
 	}
 
 	client := a.greenfieldExecutor.GetGnfdClient()
+	batch := &claimBatch{}
+	var stopErr error
 	for i := startSeq; i <= uint64(endSequence); i++ {
 		pkgs, err := a.daoManager.BSCDao.GetPackagesByOracleSequence(i)
 		if err != nil {
-			return fmt.Errorf("faield to get packages by oracle sequence %d from DB, err=%s", i, err.Error())
+			stopErr = fmt.Errorf("faield to get packages by oracle sequence %d from DB, err=%s", i, err.Error())
+			break
 		}
 		logging.Logger.Debugf("len(pkgs):%d, index:%d", len(pkgs), i)
 		if len(pkgs) == 0 {
@@ -238,40 +252,74 @@ func (a *BSCAssembler) process(channelId types.ChannelId) error {
 			a.alertSet[i] = struct{}{}
 		}
 
-		if status != db.AllVoted && status != db.Delivered {
-			return fmt.Errorf("packages with oracle sequence %d do not get enough votes yet", i)
+		if !status.IsAllVoted() {
+			stopErr = fmt.Errorf("packages with oracle sequence %d do not get enough votes yet", i)
+			break
+		}
+		if status == db.Submitted {
+			// the claim tx is pending, the tx tracker relays the packages again if it fails
+			break
 		}
 
 		// non-inturn relayer can not relay tx within the timeout of in-turn relayer
 		if !isInturnRelyer && time.Now().Unix() < pkgTime+a.config.RelayConfig.BSCToGreenfieldInturnRelayerTimeout {
 			return nil
 		}
-		if err := a.processPkgs(client, pkgs, uint8(channelId), i, a.relayerNonce, isInturnRelyer); err != nil {
-			if !isInturnRelyer {
-				return err
+		if isInturnRelyer && a.config.GreenfieldConfig.IsClaimBatchEnabled() {
+			c, err := a.prepareClaim(pkgs, uint8(channelId), i)
+			if err != nil {
+				stopErr = err
+				break
 			}
-			// There is a slight possibility that multiple batches of transactions are broadcast to the different Nodes with the same block height.
-			// say there are Node1, Node2 and cur Height is H, batch1(tx1, tx2, tx3) is broadcast on Node1, then batch2(tx4, tx5)
-			// broadcast on Node2 will fail due to inconsistency of nonce and channel sequence.
-			// Even the inturn relayer can resume crosschain delivery at next block(Because realyer would retry batch2 at block H+1). But it would
-			// waste plenty of gas. In that case, pasue the relayer 1 block. calibrate inturn relayer nonce and sequence
-			newNonce, nonceErr := a.greenfieldExecutor.GetNonceOnNextBlock()
-			if nonceErr != nil {
-				return nonceErr
+			// a zkmesbt ack package is relayed with its AckMinted calls first, and a sequence whose claim failed in a
+			// tx before is claimed alone, so neither is batched
+			if c.ack == nil && status != db.Failed {
+				if !batch.fits(c, &a.config.GreenfieldConfig) {
+					if err = a.sendClaimBatch(client, batch); err != nil {
+						return a.recalibrateInturnRelayer(err)
+					}
+				}
+				batch.add(c)
+				continue
 			}
-			a.relayerNonce = newNonce
-			newNextDeliveryOracleSeq, seqErr := a.bscExecutor.GetNextDeliveryOracleSequenceWithRetry(a.getChainId())
-			if seqErr != nil {
-				return seqErr
+		}
+		if err = a.sendClaimBatch(client, batch); err != nil {
+			return a.recalibrateInturnRelayer(err)
+		}
+		if err := a.processPkgs(client, pkgs, uint8(channelId), i, isInturnRelyer); err != nil {
+			if !isInturnRelyer {
+				return err
 			}
-			a.inturnRelayerSequenceStatus.NextDeliverySeq = newNextDeliveryOracleSeq
-			// logging.Logger.Debugf("newNextDeliveryOracleSeq %d ", newNextDeliveryOracleSeq)
-			return err
+			return a.recalibrateInturnRelayer(err)
 		}
 		logging.Logger.Infof("relayed packages with oracle sequence %d ", i)
-		a.relayerNonce++
 	}
-	return nil
+	if err = a.sendClaimBatch(client, batch); err != nil {
+		return a.recalibrateInturnRelayer(err)
+	}
+	return stopErr
+}
+
+// recalibrateInturnRelayer reads the nonce and the next delivery sequence of the in-turn relayer from chain after its
+// claim failed, err is returned if they are read.
+func (a *BSCAssembler) recalibrateInturnRelayer(err error) error {
+	// There is a slight possibility that multiple batches of transactions are broadcast to the different Nodes with the same block height.
+	// say there are Node1, Node2 and cur Height is H, batch1(tx1, tx2, tx3) is broadcast on Node1, then batch2(tx4, tx5)
+	// broadcast on Node2 will fail due to inconsistency of nonce and channel sequence.
+	// Even the inturn relayer can resume crosschain delivery at next block(Because realyer would retry batch2 at block H+1). But it would
+	// waste plenty of gas. In that case, pasue the relayer 1 block. calibrate inturn relayer nonce and sequence
+	newNonce, nonceErr := a.greenfieldExecutor.GetNonceOnNextBlock()
+	if nonceErr != nil {
+		return nonceErr
+	}
+	a.relayerNonce = newNonce
+	newNextDeliveryOracleSeq, seqErr := a.bscExecutor.GetNextDeliveryOracleSequenceWithRetry(a.getChainId())
+	if seqErr != nil {
+		return seqErr
+	}
+	a.inturnRelayerSequenceStatus.NextDeliverySeq = newNextDeliveryOracleSeq
+	// logging.Logger.Debugf("newNextDeliveryOracleSeq %d ", newNextDeliveryOracleSeq)
+	return err
 }
 
 type ZkmeSBTAckCrossChainPackage struct {
@@ -302,30 +350,80 @@ func DeserializeZkmeSBTAckPackage(serializedPackage []byte) (interface{}, error)
 	return &tp, nil
 }
 
-func (a *BSCAssembler) processPkgs(client *executor.GreenfieldClient, pkgs []*model.BscRelayPackage, channelId uint8, sequence uint64, nonce uint64, isInturnRelyer bool) error {
+// pendingClaim is the claim of the packages of one oracle sequence.
+type pendingClaim struct {
+	claim    *executor.OracleClaim
+	pkgs     []*model.BscRelayPackage
+	ack      *ZkmeSBTAckPackageStruct // set for a zkmesbt ack package
+	msgBytes uint64
+	gasLimit uint64
+}
+
+// claimBatch collects the claims of consecutive oracle sequences which are sent in one tx.
+type claimBatch struct {
+	claims   []*pendingClaim
+	msgBytes uint64
+	gasLimit uint64
+}
+
+// fits reports whether the claim can be added within the byte and gas budget of the batch, a claim always fits into an
+// empty batch.
+func (b *claimBatch) fits(c *pendingClaim, cfg *config.GreenfieldConfig) bool {
+	if len(b.claims) == 0 {
+		return true
+	}
+	if b.msgBytes+c.msgBytes > cfg.ClaimBatchMaxBytes {
+		return false
+	}
+	return cfg.ClaimBatchMaxGas == 0 || b.gasLimit+c.gasLimit <= cfg.ClaimBatchMaxGas
+}
+
+func (b *claimBatch) add(c *pendingClaim) {
+	b.claims = append(b.claims, c)
+	b.msgBytes += c.msgBytes
+	b.gasLimit += c.gasLimit
+}
+
+func (b *claimBatch) reset() {
+	b.claims = b.claims[:0]
+	b.msgBytes = 0
+	b.gasLimit = 0
+}
+
+// prepareClaim aggregates the votes for the packages of the oracle sequence into their claim.
+func (a *BSCAssembler) prepareClaim(pkgs []*model.BscRelayPackage, channelId uint8, sequence uint64) (*pendingClaim, error) {
 	// Get votes result for a packages, which are already validated and qualified to aggregate sig
 	votes, err := a.daoManager.VoteDao.GetVotesByChannelIdAndSequence(channelId, sequence)
 	if err != nil {
-		return fmt.Errorf("failed to get votes result for packages for channel %d and sequence %d", channelId, sequence)
+		return nil, fmt.Errorf("failed to get votes result for packages for channel %d and sequence %d", channelId, sequence)
 	}
 	if len(votes) == 0 {
-		return fmt.Errorf("0 votes provided")
+		return nil, fmt.Errorf("0 votes provided")
 	}
 	validators, err := a.greenfieldExecutor.QueryCachedLatestValidators()
 	if err != nil {
-		return fmt.Errorf("failed to query cached validators, err=%s", err.Error())
+		return nil, fmt.Errorf("failed to query cached validators, err=%s", err.Error())
 	}
 
 	aggregatedSignature, valBitSet, err := vote.AggregateSignatureAndValidatorBitSet(votes, validators)
 	if err != nil {
-		return fmt.Errorf("failed to aggregate signature, err=%s", err.Error())
+		return nil, fmt.Errorf("failed to aggregate signature, err=%s", err.Error())
 	}
 
 	pack, err := DeserializeRawZkmeSBTAckPackage(votes[0].ClaimPayload[sdk.AckPackageHeaderLength+ORACLETYPES_PACKAGES_PREFIX:])
 	if err != nil {
-		return fmt.Errorf("failed to deserialize raw crosschain package, err=%s", err.Error())
+		return nil, fmt.Errorf("failed to deserialize raw crosschain package, err=%s", err.Error())
+	}
+	c := &pendingClaim{
+		claim: &executor.OracleClaim{
+			Payload:        votes[0].ClaimPayload,
+			AggregatedSig:  aggregatedSignature,
+			VoteAddressSet: valBitSet.Bytes(),
+			ClaimTs:        pkgs[0].TxTime,
+			OracleSeq:      sequence,
+		},
+		pkgs: pkgs,
 	}
-	var txHash string
 	logging.Logger.Debugf("pack.OperationType %d", pack.OperationType)
 	if pack.OperationType == OperationZkmeSBTACK {
 		tp, errs := DeserializeZkmeSBTAckPackage(pack.Package)
@@ -334,49 +432,89 @@ func (a *BSCAssembler) processPkgs(client *executor.GreenfieldClient, pkgs []*mo
 		}
 		switch zkmesbtack := tp.(type) {
 		case *ZkmeSBTAckPackageStruct:
-			// TODO: for _,addr := zkmesbtack.Toaddrs{}
-			var status uint8
-			if zkmesbtack.Status == STATUS_SUCCESS {
-				status = TYPES_MIRROR_SUCCEED
-			} else {
-				status = TYPES_MIRROR_FAILED
-			}
-			tx, errs := a.greenfieldExecutor.CallZkmeSBTAckMintedContract(uint32(a.getChainId()), zkmesbtack.Toaddrs[0], status, nonce)
-			txHash = tx.String()
-			if errs != nil {
-				return fmt.Errorf("failed to Call ZkmeSBTAckMintedContract, txHash=%s, err=%s", txHash, errs.Error())
-			}
-			// zkmesbt should also IncrReceiveSequence, use nonce + 1
-			txHash, err = a.greenfieldExecutor.ClaimPackages(client, votes[0].ClaimPayload, aggregatedSignature, valBitSet.Bytes(), pkgs[0].TxTime, sequence, nonce+1)
-			a.relayerNonce++
-			logging.Logger.Debugf("CallZkmeSBTAckMintedContract chainid=%d, toaddrs[0]=%s, status=%d, nonce=%d, sequence=%d", a.getChainId(), zkmesbtack.Toaddrs[0].String(), status, nonce, sequence)
+			c.ack = zkmesbtack
 		default:
 			panic("unknown zkmesbt cross chain ack package type")
 		}
-	} else {
-		txHash, err = a.greenfieldExecutor.ClaimPackages(client, votes[0].ClaimPayload, aggregatedSignature, valBitSet.Bytes(), pkgs[0].TxTime, sequence, nonce)
 	}
+	msg := a.greenfieldExecutor.NewClaimMsg(c.claim)
+	c.msgBytes = uint64(msg.Size())
+	if c.gasLimit, err = a.greenfieldExecutor.EstimateClaimGas(msg); err != nil {
+		return nil, fmt.Errorf("failed to estimate claim gas, err=%s", err.Error())
+	}
+	return c, nil
+}
+
+// processPkgs claims the packages of the oracle sequence with the nonce of the relayer. The acks of a zkmesbt ack package
+// are relayed first, and the nonce of the relayer is moved past the acks and the claim which were sent.
+func (a *BSCAssembler) processPkgs(client *executor.GreenfieldClient, pkgs []*model.BscRelayPackage, channelId uint8, sequence uint64, isInturnRelyer bool) error {
+	c, err := a.prepareClaim(pkgs, channelId, sequence)
+	if err != nil {
+		return err
+	}
+	nonce := a.relayerNonce
+	if c.ack != nil {
+		nonce, err = ackZkmeSBT(a.greenfieldExecutor, uint32(a.getChainId()), c.ack, sequence, nonce,
+			a.config.RelayConfig.GetAckTxTimeout())
+		if err != nil {
+			return err
+		}
+		a.relayerNonce = nonce
+	}
+	txHash, err := a.greenfieldExecutor.ClaimPackages(client, []*executor.OracleClaim{c.claim}, nonce)
 	if err != nil {
 		return fmt.Errorf("failed to claim packages, txHash=%s, err=%s", txHash, err.Error())
 	}
 	logging.Logger.Infof("claimed transaction with oracle_sequence=%d, txHash=%s", sequence, txHash)
+	if err = a.markClaimsSubmitted([]*pendingClaim{c}, txHash, isInturnRelyer); err != nil {
+		return err
+	}
+	a.relayerNonce = nonce + 1
+	return nil
+}
 
-	var pkgIds []int64
-	for _, p := range pkgs {
-		pkgIds = append(pkgIds, p.Id)
+// sendClaimBatch sends the claims of the batch in one tx with the nonce of the in-turn relayer, and empties the batch.
+func (a *BSCAssembler) sendClaimBatch(client *executor.GreenfieldClient, batch *claimBatch) error {
+	if len(batch.claims) == 0 {
+		return nil
+	}
+	defer batch.reset()
+	claims := make([]*executor.OracleClaim, 0, len(batch.claims))
+	for _, c := range batch.claims {
+		claims = append(claims, c.claim)
+	}
+	startSeq, endSeq := claims[0].OracleSeq, claims[len(claims)-1].OracleSeq
+	txHash, err := a.greenfieldExecutor.ClaimPackages(client, claims, a.relayerNonce)
+	if err != nil {
+		return fmt.Errorf("failed to claim packages with oracle sequences %d-%d, err=%s", startSeq, endSeq, err.Error())
+	}
+	logging.Logger.Infof("claimed transaction with oracle_sequence=%d-%d, msgs=%d, bytes=%d, txHash=%s",
+		startSeq, endSeq, len(claims), batch.msgBytes, txHash)
+	if err = a.markClaimsSubmitted(batch.claims, txHash, true); err != nil {
+		return err
 	}
-	a.metricService.SetBSCProcessedBlockHeight(pkgs[0].Height)
+	a.relayerNonce++
+	return nil
+}
 
-	if !isInturnRelyer {
-		if err = a.daoManager.BSCDao.UpdateBatchPackagesClaimedTxHash(pkgIds, txHash); err != nil {
-			return fmt.Errorf("failed to update batch packages and claimedTxHash, err=%s", err.Error())
+// markClaimsSubmitted records the tx the claims were sent in. The packages are marked confirmed by the tx tracker once
+// the tx result is known.
+func (a *BSCAssembler) markClaimsSubmitted(claims []*pendingClaim, txHash string, isInturnRelyer bool) error {
+	var pkgIds []int64
+	for _, c := range claims {
+		for _, p := range c.pkgs {
+			pkgIds = append(pkgIds, p.Id)
 		}
-		return nil
 	}
-	if err = a.daoManager.BSCDao.UpdateBatchPackagesStatusAndClaimedTxHash(pkgIds, db.Delivered, txHash); err != nil {
-		return fmt.Errorf("failed to update packages to 'Delivered', error=%s", err.Error())
+	last := claims[len(claims)-1]
+	a.metricService.SetBSCProcessedBlockHeight(last.pkgs[0].Height)
+
+	if err := a.daoManager.BSCDao.UpdateBatchPackagesStatusAndClaimedTxHash(pkgIds, db.Submitted, txHash); err != nil {
+		return fmt.Errorf("failed to update packages to 'Submitted', error=%s", err.Error())
+	}
+	if isInturnRelyer {
+		a.inturnRelayerSequenceStatus.NextDeliverySeq = last.claim.OracleSeq + 1
 	}
-	a.inturnRelayerSequenceStatus.NextDeliverySeq = sequence + 1
 	return nil
 }
 
@@ -0,0 +1,31 @@
+package assembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+)
+
+func TestClaimBatchFits(t *testing.T) {
+	cfg := &config.GreenfieldConfig{ClaimBatchMaxBytes: 1000, ClaimBatchMaxGas: 500}
+	batch := &claimBatch{}
+
+	// a claim always fits into an empty batch
+	require.True(t, batch.fits(&pendingClaim{msgBytes: 2000, gasLimit: 1000}, cfg))
+
+	batch.add(&pendingClaim{msgBytes: 600, gasLimit: 200})
+	require.True(t, batch.fits(&pendingClaim{msgBytes: 400, gasLimit: 300}, cfg))
+	require.False(t, batch.fits(&pendingClaim{msgBytes: 401, gasLimit: 100}, cfg))
+	require.False(t, batch.fits(&pendingClaim{msgBytes: 100, gasLimit: 301}, cfg))
+
+	// no gas budget
+	cfg.ClaimBatchMaxGas = 0
+	require.True(t, batch.fits(&pendingClaim{msgBytes: 100, gasLimit: 100000}, cfg))
+
+	batch.reset()
+	require.Empty(t, batch.claims)
+	require.Zero(t, batch.msgBytes)
+	require.Zero(t, batch.gasLimit)
+}
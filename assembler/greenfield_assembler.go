@@ -34,12 +34,11 @@ type GreenfieldAssembler struct {
 	bscExecutor                    *executor.BSCExecutor
 	greenfieldExecutor             *executor.GreenfieldExecutor
 	daoManager                     *dao.DaoManager
-	blsPubKey                      []byte
 	inturnRelayerSequenceStatusMap map[types.ChannelId]*types.SequenceStatus // flag for in-turn relayer that if it has requested the sequence from chain during its interval
-	relayerNonceStatus             *types.NonceStatus
 	metricService                  *metric.MetricService
 	alertSetMutex                  sync.RWMutex
 	alertSet                       map[AlertKey]struct{}
+	cappedTxs                      map[int64]struct{} // ids of the claim txs alerted for as stuck at max_gas_price
 }
 
 func NewGreenfieldAssembler(cfg *config.Config, executor *executor.GreenfieldExecutor, dao *dao.DaoManager, bscExecutor *executor.BSCExecutor,
@@ -56,11 +55,10 @@ func NewGreenfieldAssembler(cfg *config.Config, executor *executor.GreenfieldExe
 		greenfieldExecutor:             executor,
 		daoManager:                     dao,
 		bscExecutor:                    bscExecutor,
-		blsPubKey:                      executor.BlsPubKey,
 		inturnRelayerSequenceStatusMap: inturnRelayerSequenceStatusMap,
-		relayerNonceStatus:             &types.NonceStatus{},
 		metricService:                  ms,
 		alertSet:                       make(map[AlertKey]struct{}, 0),
+		cappedTxs:                      make(map[int64]struct{}),
 	}
 }
 
@@ -68,36 +66,34 @@ func NewGreenfieldAssembler(cfg *config.Config, executor *executor.GreenfieldExe
 func (a *GreenfieldAssembler) AssembleTransactionsLoop() {
 	ticker := time.NewTicker(common.AssembleInterval)
 	for range ticker.C {
-		inturnRelayer, err := a.bscExecutor.GetInturnRelayer()
-		if err != nil {
-			logging.Logger.Errorf("encounter error when retrieving in-turn relayer from chain, err=%s ", err.Error())
-			continue
-		}
-		inturnRelayerPubkey, err := hex.DecodeString(inturnRelayer.BlsPublicKey)
-		if err != nil {
-			logging.Logger.Errorf("encounter error when decode in-turn relayer key, err=%s ", err.Error())
-			continue
-		}
-		isInturnRelyer := bytes.Equal(a.blsPubKey, inturnRelayerPubkey)
-		a.metricService.SetBSCInturnRelayerMetrics(isInturnRelyer, inturnRelayer.Start, inturnRelayer.End)
+		a.assembleTransactions()
+	}
+}
 
-		// logging.Logger.Debugf("a.relayerNonceStatus.HasRetrieved=%t, isInturnRelyer=%t", a.relayerNonceStatus.HasRetrieved, isInturnRelyer)
-		if (isInturnRelyer && !a.relayerNonceStatus.HasRetrieved) || !isInturnRelyer {
-			nonce, err := a.bscExecutor.GetNonce()
-			if err != nil {
-				logging.Logger.Errorf("encounter error when get relayer nonce, err=%s ", err.Error())
-				continue
-			}
-			a.relayerNonceStatus.Nonce = nonce
-		}
+func (a *GreenfieldAssembler) assembleTransactions() {
+	a.greenfieldExecutor.BeginRelay()
+	defer a.greenfieldExecutor.EndRelay()
 
-		wg := new(sync.WaitGroup)
-		for _, c := range a.getMonitorChannels() {
-			wg.Add(1)
-			go a.assembleTransactionAndSendForChannel(types.ChannelId(c), inturnRelayer, isInturnRelyer, wg)
-		}
-		wg.Wait()
+	inturnRelayer, err := a.bscExecutor.GetInturnRelayer()
+	if err != nil {
+		logging.Logger.Errorf("encounter error when retrieving in-turn relayer from chain, err=%s ", err.Error())
+		return
+	}
+	inturnRelayerPubkey, err := hex.DecodeString(inturnRelayer.BlsPublicKey)
+	if err != nil {
+		logging.Logger.Errorf("encounter error when decode in-turn relayer key, err=%s ", err.Error())
+		return
 	}
+	isInturnRelyer := bytes.Equal(a.greenfieldExecutor.GetBlsPubKey(), inturnRelayerPubkey)
+	a.metricService.SetBSCInturnRelayerMetrics(isInturnRelyer, inturnRelayer.Start, inturnRelayer.End)
+
+	// the channels are relayed concurrently, the nonces are allocated by the nonce manager of the BSC executor
+	wg := new(sync.WaitGroup)
+	for _, c := range a.getMonitorChannels() {
+		wg.Add(1)
+		go a.assembleTransactionAndSendForChannel(types.ChannelId(c), inturnRelayer, isInturnRelyer, wg)
+	}
+	wg.Wait()
 }
 
 func (a *GreenfieldAssembler) assembleTransactionAndSendForChannel(channelId types.ChannelId, inturnRelayer *types.InturnRelayer, isInturnRelyer bool, wg *sync.WaitGroup) {
@@ -152,7 +148,7 @@ func (a *GreenfieldAssembler) process(channelId types.ChannelId, inturnRelayer *
 	}
 
 	if isInturnRelyer {
-		endSequence, err = a.daoManager.GreenfieldDao.GetLatestSequenceByChannelIdAndStatus(channelId, db.AllVoted)
+		endSequence, err = a.daoManager.GreenfieldDao.GetLatestSequenceByChannelIdAndStatus(channelId, db.AllVoted, db.Failed)
 		if err != nil {
 			return fmt.Errorf("faield to get latest sequence from DB, err=%s", err.Error())
 		}
@@ -209,24 +205,25 @@ func (a *GreenfieldAssembler) process(channelId types.ChannelId, inturnRelayer *
 			a.alertSetMutex.Unlock()
 		}
 
-		if tx.Status != db.AllVoted && tx.Status != db.Delivered {
+		if !tx.Status.IsAllVoted() {
 			return fmt.Errorf("tx with channel id %d and sequence %d does not get enough votes yet", tx.ChannelId, tx.Sequence)
 		}
+		if tx.Status == db.Submitted {
+			// the claim tx is pending, the tx tracker relays it again if it fails
+			return nil
+		}
 		if !isInturnRelyer && time.Now().Unix() < tx.TxTime+a.config.RelayConfig.GreenfieldToBSCInturnRelayerTimeout {
 			return nil
 		}
-		if err := a.processTx(tx, a.relayerNonceStatus.Nonce, isInturnRelyer); err != nil {
+		if err := a.processTx(tx, isInturnRelyer); err != nil {
 			return err
 		}
 		logging.Logger.Infof("relayed tx with channel id %d and sequence %d ", tx.ChannelId, tx.Sequence)
-		a.mutex.Lock()
-		a.relayerNonceStatus.Nonce++
-		a.mutex.Unlock()
 	}
 	return nil
 }
 
-func (a *GreenfieldAssembler) processTx(tx *model.GreenfieldRelayTransaction, nonce uint64, isInturnRelyer bool) error {
+func (a *GreenfieldAssembler) processTx(tx *model.GreenfieldRelayTransaction, isInturnRelyer bool) error {
 	// Get votes result for a tx, which are already validated and qualified to aggregate sig
 	votes, err := a.daoManager.VoteDao.GetVotesByChannelIdAndSequence(tx.ChannelId, tx.Sequence)
 	if err != nil {
@@ -278,9 +275,9 @@ func (a *GreenfieldAssembler) processTx(tx *model.GreenfieldRelayTransaction, no
 	signature, _ := sig.Marshal()
 	logging.Logger.Debugf("pubKeyNumber=%d, len(relayerAddresses)=%d, valBitSet=%v, SignatureFromBytes=%s, serialize=%s", pubKeyNumber, len(relayerAddresses), valBitSet.Bytes(), hex.EncodeToString(signature), hex.EncodeToString(signature))
 	// sigPubkeys := append(signature, pubKeys...)
-	txHash, err := a.bscExecutor.CallBuildInSystemContract(signature, util.BitSetToBigInt(valBitSet), votes[0].ClaimPayload, nonce)
+	txHash, err := a.bscExecutor.CallBuildInSystemContract(signature, util.BitSetToBigInt(valBitSet), votes[0].ClaimPayload)
 	if err != nil {
-		return fmt.Errorf("failed to submit tx to BSC, nonce=%d, txHash=%s, err=%s", nonce, txHash, err.Error())
+		return fmt.Errorf("failed to submit tx to BSC, err=%s", err.Error())
 	}
 
 	logging.Logger.Infof("relayed transaction with channel id %d and sequence %d, txHash=%s", tx.ChannelId, tx.Sequence, txHash)
@@ -288,13 +285,8 @@ func (a *GreenfieldAssembler) processTx(tx *model.GreenfieldRelayTransaction, no
 
 	// update next delivery sequence in DB for inturn relayer, for non-inturn relayer, there is enough time for
 	// sequence update, so they can track next start seq from chain
-	if !isInturnRelyer {
-		if err = a.daoManager.GreenfieldDao.UpdateTransactionClaimedTxHash(tx.Id, txHash.String()); err != nil {
-			return fmt.Errorf("failed to update transaciton status, err=%s", err.Error())
-		}
-	}
-
-	if err = a.daoManager.GreenfieldDao.UpdateTransactionStatusAndClaimedTxHash(tx.Id, db.Delivered, txHash.String()); err != nil {
+	// the tx is marked confirmed by the tx tracker once its receipt is known
+	if err = a.daoManager.GreenfieldDao.UpdateTransactionStatusAndClaimedTxHash(tx.Id, db.Submitted, txHash.String()); err != nil {
 		return fmt.Errorf("failed to update transaciton status, err=%s", err.Error())
 	}
 	a.mutex.Lock()
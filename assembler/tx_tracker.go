@@ -0,0 +1,299 @@
+package assembler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/zkMeLabs/mechain-relayer/common"
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/db"
+	"github.com/zkMeLabs/mechain-relayer/db/model"
+	"github.com/zkMeLabs/mechain-relayer/executor/gas"
+	"github.com/zkMeLabs/mechain-relayer/logging"
+	"github.com/zkMeLabs/mechain-relayer/metric"
+	"github.com/zkMeLabs/mechain-relayer/types"
+)
+
+// TrackTransactionsLoop polls the receipts of the claim txs sent to BSC and records the gas used by the included ones. A
+// tx which succeeded is marked Confirmed, a tx which reverted, or which is still unknown to the node after
+// PendingTxTimeout, is marked Failed and its sequence is relayed again, unless the sequence has been delivered by another
+// relayer meanwhile. A tx pending for longer than stuck_tx_timeout is replaced by one with the same nonce and a bumped gas
+// price, it stays Submitted as long as it is in the pool, and an alert is raised if max_gas_price stops its replacement.
+func (a *GreenfieldAssembler) TrackTransactionsLoop() {
+	ticker := time.NewTicker(common.TrackTxInterval)
+	for range ticker.C {
+		txs, err := a.daoManager.GreenfieldDao.GetTransactionsByStatusWithLimit(db.Submitted, common.TrackTxBatchSize)
+		if err != nil {
+			logging.Logger.Errorf("failed to get submitted transactions from db, err=%s", err.Error())
+			continue
+		}
+		// a stuck tx is replaced with the relayer key
+		a.greenfieldExecutor.BeginRelay()
+		for _, tx := range txs {
+			if err = a.trackTransaction(tx); err != nil {
+				logging.Logger.Errorf("failed to track tx with channel id %d and sequence %d, txHash=%s, err=%s",
+					tx.ChannelId, tx.Sequence, tx.ClaimedTxHash, err.Error())
+			}
+		}
+		a.greenfieldExecutor.EndRelay()
+	}
+}
+
+func (a *GreenfieldAssembler) trackTransaction(tx *model.GreenfieldRelayTransaction) error {
+	receipt, err := a.bscExecutor.GetTransactionReceipt(ethcommon.HexToHash(tx.ClaimedTxHash))
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return err
+	}
+	if err == nil {
+		delete(a.cappedTxs, tx.Id)
+		a.metricService.RecordBSCGasUsed(metric.BSCTxHandlePackage, receipt.GasUsed)
+	}
+	if err == nil && receipt.Status == ethtypes.ReceiptStatusSuccessful {
+		logging.Logger.Infof("confirmed tx with channel id %d and sequence %d, txHash=%s", tx.ChannelId, tx.Sequence, tx.ClaimedTxHash)
+		return a.daoManager.GreenfieldDao.UpdateTransactionStatus(tx.Id, db.Confirmed)
+	}
+	if err != nil {
+		pendingTime := time.Since(time.Unix(tx.UpdatedTime, 0))
+		state, replacement, err := checkPendingTx(a.bscExecutor, ethcommon.HexToHash(tx.ClaimedTxHash), pendingTime,
+			a.config.BSCConfig.GetStuckTxTimeout())
+		if err != nil {
+			return err
+		}
+		if state == txCapped {
+			a.alertCappedTx(tx)
+			return nil
+		}
+		delete(a.cappedTxs, tx.Id)
+		if state == txReplaced {
+			a.recordReplacement(tx, replacement)
+		}
+		if state != txDropped {
+			return nil
+		}
+		// the nonce of the dropped tx is free again
+		a.bscExecutor.ResyncNonce()
+		logging.Logger.Errorf("tx with channel id %d and sequence %d is dropped, txHash=%s", tx.ChannelId, tx.Sequence, tx.ClaimedTxHash)
+	} else {
+		logging.Logger.Errorf("tx with channel id %d and sequence %d reverted, txHash=%s", tx.ChannelId, tx.Sequence, tx.ClaimedTxHash)
+	}
+
+	channelId := types.ChannelId(tx.ChannelId)
+	nextDeliverySeq, err := a.greenfieldExecutor.GetNextDeliverySequenceForChannelWithRetry(channelId)
+	if err != nil {
+		return err
+	}
+	if tx.Sequence < nextDeliverySeq {
+		return a.daoManager.GreenfieldDao.UpdateTransactionStatus(tx.Id, db.Delivered)
+	}
+	if err = a.daoManager.GreenfieldDao.UpdateTransactionStatus(tx.Id, db.Failed); err != nil {
+		return err
+	}
+	// the in-turn relayer restarts from the sequence on chain
+	a.mutex.Lock()
+	a.inturnRelayerSequenceStatusMap[channelId].HasRetrieved = false
+	a.mutex.Unlock()
+	return nil
+}
+
+// pendingTxHandler is the part of the BSCExecutor a claim tx without receipt is handled with.
+type pendingTxHandler interface {
+	IsTransactionDropped(txHash ethcommon.Hash) (bool, error)
+	ReplaceTransaction(txHash ethcommon.Hash) (ethcommon.Hash, error)
+}
+
+// pendingTxState is the state of a claim tx without receipt.
+type pendingTxState int
+
+const (
+	txPending pendingTxState = iota
+	txReplaced
+	txCapped // the tx is in the pool but the gas price ceiling does not allow to replace it
+	txDropped
+)
+
+// checkPendingTx handles a claim tx which has no receipt after pendingTime. A tx pending for longer than stuckTimeout is
+// replaced by one with a bumped gas price, and the hash of the replacement is returned. A tx is only dropped if the node
+// does not know it after PendingTxTimeout, a tx which is still in the pool is pending however long it waits. It is
+// txCapped if the gas price ceiling keeps it from being replaced, otherwise the error of its replacement is returned.
+func checkPendingTx(h pendingTxHandler, txHash ethcommon.Hash, pendingTime, stuckTimeout time.Duration) (pendingTxState, ethcommon.Hash, error) {
+	if pendingTime < stuckTimeout {
+		return txPending, ethcommon.Hash{}, nil
+	}
+	dropped, err := h.IsTransactionDropped(txHash)
+	if err != nil {
+		return txPending, ethcommon.Hash{}, fmt.Errorf("failed to look up pending tx, err=%s", err.Error())
+	}
+	if dropped {
+		// the tx may not have reached the pool of the node yet
+		if pendingTime < common.PendingTxTimeout {
+			return txPending, ethcommon.Hash{}, nil
+		}
+		return txDropped, ethcommon.Hash{}, nil
+	}
+	replacement, err := h.ReplaceTransaction(txHash)
+	if errors.Is(err, gas.ErrGasPriceCeiling) {
+		return txCapped, ethcommon.Hash{}, nil
+	}
+	if err != nil {
+		return txPending, ethcommon.Hash{}, fmt.Errorf("failed to replace stuck tx, err=%s", err.Error())
+	}
+	return txReplaced, replacement, nil
+}
+
+// alertCappedTx reports a claim tx which stays pending because max_gas_price keeps it from being replaced, the alert is
+// sent once per tx and the tx is logged on every check.
+func (a *GreenfieldAssembler) alertCappedTx(tx *model.GreenfieldRelayTransaction) {
+	msg := fmt.Sprintf("tx with channel id %d and sequence %d is stuck at the max gas price, txHash=%s",
+		tx.ChannelId, tx.Sequence, tx.ClaimedTxHash)
+	logging.Logger.Error(msg)
+	if _, ok := a.cappedTxs[tx.Id]; ok {
+		return
+	}
+	a.cappedTxs[tx.Id] = struct{}{}
+	config.SendTelegramMessage(a.config.AlertConfig.Identity, a.config.AlertConfig.TelegramBotId,
+		a.config.AlertConfig.TelegramChatId, msg)
+}
+
+// recordReplacement records the hash of the replacement of a stuck claim tx.
+func (a *GreenfieldAssembler) recordReplacement(tx *model.GreenfieldRelayTransaction, replacement ethcommon.Hash) {
+	logging.Logger.Infof("replaced stuck tx with channel id %d and sequence %d, txHash=%s, replacement txHash=%s",
+		tx.ChannelId, tx.Sequence, tx.ClaimedTxHash, replacement.String())
+	// the replacement is tracked from now on, if the replaced tx is included instead it is found delivered on chain
+	if err := a.daoManager.GreenfieldDao.UpdateTransactionClaimedTxHash(tx.Id, replacement.String()); err != nil {
+		logging.Logger.Errorf("failed to update claimed tx hash with channel id %d and sequence %d, txHash=%s, err=%s",
+			tx.ChannelId, tx.Sequence, replacement.String(), err.Error())
+	}
+}
+
+// TrackPackagesLoop polls the results of the claim txs sent to Mechain. A tx which succeeded is marked Confirmed, a tx
+// which failed or is not included within PendingTxTimeout is marked Failed and its packages are relayed again, unless
+// the oracle sequence has been delivered by another relayer meanwhile.
+func (a *BSCAssembler) TrackPackagesLoop() {
+	ticker := time.NewTicker(common.TrackTxInterval)
+	for range ticker.C {
+		pkgs, err := a.daoManager.BSCDao.GetPackagesByStatus(db.Submitted)
+		if err != nil {
+			logging.Logger.Errorf("failed to get submitted packages from db, err=%s", err.Error())
+			continue
+		}
+		pkgsGroupByTxHash := make(map[string][]*model.BscRelayPackage)
+		for _, pkg := range pkgs {
+			pkgsGroupByTxHash[pkg.ClaimTxHash] = append(pkgsGroupByTxHash[pkg.ClaimTxHash], pkg)
+		}
+		for txHash, pkgsForTx := range pkgsGroupByTxHash {
+			if err = a.trackClaimTx(txHash, pkgsForTx); err != nil {
+				logging.Logger.Errorf("failed to track packages of claim tx, txHash=%s, err=%s", txHash, err.Error())
+			}
+		}
+	}
+}
+
+// trackClaimTx tracks the tx which claimed the packages of one or more consecutive oracle sequences. The msgs of the tx
+// are in the order of the sequences, so the msg a failure is reported for is mapped back to its sequence, see
+// claimTxStatuses.
+func (a *BSCAssembler) trackClaimTx(txHash string, pkgs []*model.BscRelayPackage) error {
+	seqSet := make(map[uint64]struct{})
+	seqs := make([]uint64, 0)
+	for _, p := range pkgs {
+		if _, ok := seqSet[p.OracleSequence]; !ok {
+			seqSet[p.OracleSequence] = struct{}{}
+			seqs = append(seqs, p.OracleSequence)
+		}
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	res, err := a.greenfieldExecutor.GetTxResult(txHash)
+	if err != nil {
+		return err
+	}
+	if res != nil && res.TxResult.Code == 0 {
+		logging.Logger.Infof("confirmed packages with oracle sequences %v, txHash=%s", seqs, txHash)
+		return a.daoManager.BSCDao.UpdateBatchPackagesStatus(packageIds(pkgs), db.Confirmed)
+	}
+	failedIdx := -1
+	if res == nil {
+		if time.Since(time.Unix(pkgs[0].UpdatedTime, 0)) < common.PendingTxTimeout {
+			return nil
+		}
+		logging.Logger.Errorf("packages with oracle sequences %v are dropped, txHash=%s", seqs, txHash)
+	} else if idx, ok := failedMsgIndex(res.TxResult.Log); ok && idx < len(seqs) {
+		failedIdx = idx
+		logging.Logger.Errorf("claim of oracle sequence %d failed, packages with oracle sequences %v are relayed again, txHash=%s, code=%d, log=%s",
+			seqs[idx], seqs, txHash, res.TxResult.Code, res.TxResult.Log)
+	} else {
+		logging.Logger.Errorf("packages with oracle sequences %v failed, txHash=%s, code=%d, log=%s", seqs, txHash, res.TxResult.Code, res.TxResult.Log)
+	}
+
+	nextDeliverySeq, err := a.bscExecutor.GetNextDeliveryOracleSequenceWithRetry(a.getChainId())
+	if err != nil {
+		return err
+	}
+	statuses := claimTxStatuses(seqs, failedIdx, nextDeliverySeq)
+	pkgIdsByStatus := make(map[db.TxStatus][]int64)
+	for _, p := range pkgs {
+		status := statuses[p.OracleSequence]
+		pkgIdsByStatus[status] = append(pkgIdsByStatus[status], p.Id)
+	}
+	for _, status := range []db.TxStatus{db.Delivered, db.AllVoted, db.Failed} {
+		if ids := pkgIdsByStatus[status]; len(ids) > 0 {
+			if err = a.daoManager.BSCDao.UpdateBatchPackagesStatus(ids, status); err != nil {
+				return err
+			}
+		}
+	}
+	if len(pkgIdsByStatus[db.Delivered]) < len(pkgs) {
+		// the in-turn relayer restarts from the oracle sequence and the nonce on chain
+		a.resync.Store(true)
+	}
+	return nil
+}
+
+// claimTxStatuses maps the result of a failed or dropped claim tx back to the statuses of the oracle sequences it
+// claimed, in the order of its msgs. A sequence delivered meanwhile by another relayer is Delivered. If the failure is
+// reported for the msg at failedIdx, its sequence is Failed and is claimed alone next time, the other sequences were
+// only rolled back with it and are AllVoted to be batched again. Otherwise, e.g. for a dropped tx, failedIdx is -1 and
+// all of them are Failed.
+func claimTxStatuses(seqs []uint64, failedIdx int, nextDeliverySeq uint64) map[uint64]db.TxStatus {
+	statuses := make(map[uint64]db.TxStatus, len(seqs))
+	for i, seq := range seqs {
+		switch {
+		case seq < nextDeliverySeq:
+			statuses[seq] = db.Delivered
+		case failedIdx >= 0 && i != failedIdx:
+			statuses[seq] = db.AllVoted
+		default:
+			statuses[seq] = db.Failed
+		}
+	}
+	return statuses
+}
+
+func packageIds(pkgs []*model.BscRelayPackage) []int64 {
+	ids := make([]int64, 0, len(pkgs))
+	for _, p := range pkgs {
+		ids = append(ids, p.Id)
+	}
+	return ids
+}
+
+var failedMsgIndexRegexp = regexp.MustCompile(`message index: (\d+)`)
+
+// failedMsgIndex returns the index of the msg a tx failed on, as reported in the log of the tx result.
+func failedMsgIndex(log string) (int, bool) {
+	m := failedMsgIndexRegexp.FindStringSubmatch(log)
+	if m == nil {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	return idx, true
+}
@@ -0,0 +1,157 @@
+package assembler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-relayer/common"
+	"github.com/zkMeLabs/mechain-relayer/db"
+	"github.com/zkMeLabs/mechain-relayer/executor/gas"
+)
+
+func TestFailedMsgIndex(t *testing.T) {
+	idx, ok := failedMsgIndex("failed to execute message; message index: 2: sequence mismatch")
+	require.True(t, ok)
+	require.Equal(t, 2, idx)
+
+	_, ok = failedMsgIndex("out of gas in location: WritePerByte")
+	require.False(t, ok)
+
+	_, ok = failedMsgIndex("")
+	require.False(t, ok)
+}
+
+func TestClaimTxStatuses(t *testing.T) {
+	seqs := []uint64{10, 11, 12, 13}
+	for _, tc := range []struct {
+		name            string
+		failedIdx       int
+		nextDeliverySeq uint64
+		expected        map[uint64]db.TxStatus
+	}{
+		{
+			name:            "failed msg",
+			failedIdx:       2,
+			nextDeliverySeq: 10,
+			expected:        map[uint64]db.TxStatus{10: db.AllVoted, 11: db.AllVoted, 12: db.Failed, 13: db.AllVoted},
+		},
+		{
+			name:            "failed msg delivered meanwhile",
+			failedIdx:       1,
+			nextDeliverySeq: 12,
+			expected:        map[uint64]db.TxStatus{10: db.Delivered, 11: db.Delivered, 12: db.AllVoted, 13: db.AllVoted},
+		},
+		{
+			name:            "dropped or unknown msg",
+			failedIdx:       -1,
+			nextDeliverySeq: 11,
+			expected:        map[uint64]db.TxStatus{10: db.Delivered, 11: db.Failed, 12: db.Failed, 13: db.Failed},
+		},
+		{
+			name:            "all delivered",
+			failedIdx:       -1,
+			nextDeliverySeq: 14,
+			expected:        map[uint64]db.TxStatus{10: db.Delivered, 11: db.Delivered, 12: db.Delivered, 13: db.Delivered},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, claimTxStatuses(seqs, tc.failedIdx, tc.nextDeliverySeq))
+		})
+	}
+}
+
+type fakePendingTxHandler struct {
+	dropped    bool
+	lookupErr  error
+	replaceErr error
+	replaced   []ethcommon.Hash
+}
+
+func (f *fakePendingTxHandler) IsTransactionDropped(ethcommon.Hash) (bool, error) {
+	return f.dropped, f.lookupErr
+}
+
+func (f *fakePendingTxHandler) ReplaceTransaction(txHash ethcommon.Hash) (ethcommon.Hash, error) {
+	if f.replaceErr != nil {
+		return ethcommon.Hash{}, f.replaceErr
+	}
+	f.replaced = append(f.replaced, txHash)
+	return ethcommon.HexToHash("0x02"), nil
+}
+
+func TestCheckPendingTx(t *testing.T) {
+	txHash := ethcommon.HexToHash("0x01")
+	const stuckTimeout = time.Minute
+	for _, tc := range []struct {
+		name        string
+		handler     *fakePendingTxHandler
+		pendingTime time.Duration
+		state       pendingTxState
+		replaced    int
+		wantErr     bool
+	}{
+		{
+			name:        "not stuck yet",
+			handler:     &fakePendingTxHandler{},
+			pendingTime: stuckTimeout / 2,
+			state:       txPending,
+		},
+		{
+			name:        "stuck tx is replaced",
+			handler:     &fakePendingTxHandler{},
+			pendingTime: stuckTimeout,
+			state:       txReplaced,
+			replaced:    1,
+		},
+		{
+			name:        "ceiling capped tx in the pool stays pending",
+			handler:     &fakePendingTxHandler{replaceErr: gas.ErrGasPriceCeiling},
+			pendingTime: 2 * common.PendingTxTimeout,
+			state:       txCapped,
+		},
+		{
+			name:        "failed replacement of a tx in the pool is returned",
+			handler:     &fakePendingTxHandler{replaceErr: errors.New("rpc error")},
+			pendingTime: 2 * common.PendingTxTimeout,
+			state:       txPending,
+			wantErr:     true,
+		},
+		{
+			name:        "tx stays pending when the lookup fails",
+			handler:     &fakePendingTxHandler{lookupErr: errors.New("rpc error")},
+			pendingTime: 2 * common.PendingTxTimeout,
+			state:       txPending,
+			wantErr:     true,
+		},
+		{
+			name:        "unknown tx is not dropped before the pending timeout",
+			handler:     &fakePendingTxHandler{dropped: true},
+			pendingTime: stuckTimeout,
+			state:       txPending,
+		},
+		{
+			name:        "unknown tx is dropped after the pending timeout",
+			handler:     &fakePendingTxHandler{dropped: true},
+			pendingTime: common.PendingTxTimeout,
+			state:       txDropped,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			state, replacement, err := checkPendingTx(tc.handler, txHash, tc.pendingTime, stuckTimeout)
+			require.Equal(t, tc.state, state)
+			require.Len(t, tc.handler.replaced, tc.replaced)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			if tc.state == txReplaced {
+				require.Equal(t, ethcommon.HexToHash("0x02"), replacement)
+			}
+		})
+	}
+}
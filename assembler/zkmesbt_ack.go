@@ -0,0 +1,92 @@
+package assembler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/zkMeLabs/mechain-relayer/logging"
+)
+
+// zkmeSBTAcker is the part of the GreenfieldExecutor the acks of a zkmesbt ack package are relayed with.
+type zkmeSBTAcker interface {
+	GetZkmeSBTCrossChainStatus(chainId uint32, user ethcommon.Address) (uint8, error)
+	CallZkmeSBTAckMintedContract(chainId uint32, user ethcommon.Address, status uint8, nonce uint64) (ethcommon.Hash, error)
+	WaitForEvmTxReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error)
+}
+
+// ackMintedStatus returns the cross chain status the addresses of a zkmesbt ack package are acked with.
+func ackMintedStatus(status uint32) uint8 {
+	if status == STATUS_SUCCESS {
+		return TYPES_MIRROR_SUCCEED
+	}
+	return TYPES_MIRROR_FAILED
+}
+
+// ackZkmeSBT relays the zkmesbt ack package of the oracle sequence to the zkme crosschain contract before the claim of
+// the sequence. AckMinted is sent for every address of the package at consecutive nonces from nonce, and the txs are
+// waited for, so the claim is only sent once all of them succeeded. An address whose cross chain status is already the
+// acked one is skipped, so if the acks or the claim failed, the sequence is relayed again without acking an address twice.
+// The acks are waited for up to timeout, which blocks the relay round meanwhile. It returns the nonce following the
+// sent acks.
+func ackZkmeSBT(acker zkmeSBTAcker, chainId uint32, ack *ZkmeSBTAckPackageStruct, sequence, nonce uint64, timeout time.Duration) (uint64, error) {
+	status := ackMintedStatus(ack.Status)
+	seen := make(map[ethcommon.Address]struct{}, len(ack.Toaddrs))
+	txHashes := make([]ethcommon.Hash, 0, len(ack.Toaddrs))
+	var sendErr error
+	for _, addr := range ack.Toaddrs {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		crossChainStatus, err := acker.GetZkmeSBTCrossChainStatus(chainId, addr)
+		if err != nil {
+			sendErr = fmt.Errorf("failed to get cross chain status of %s, err=%s", addr.Hex(), err.Error())
+			break
+		}
+		if crossChainStatus == status {
+			logging.Logger.Debugf("zkmesbt of %s is already acked with status %d, oracle sequence %d", addr.Hex(), status, sequence)
+			continue
+		}
+		txHash, err := acker.CallZkmeSBTAckMintedContract(chainId, addr, status, nonce)
+		if err != nil {
+			sendErr = fmt.Errorf("failed to Call ZkmeSBTAckMintedContract for %s, err=%s", addr.Hex(), err.Error())
+			break
+		}
+		logging.Logger.Infof("sent zkmesbt ack of %s with oracle sequence %d, status=%d, nonce=%d, txHash=%s",
+			addr.Hex(), sequence, status, nonce, txHash.Hex())
+		txHashes = append(txHashes, txHash)
+		nonce++
+	}
+	// the sent acks are waited for even if the others could not be sent, so the next attempt finds them on chain
+	if err := waitForAcks(acker, txHashes, sequence, timeout); err != nil {
+		return nonce, err
+	}
+	if sendErr != nil {
+		return nonce, fmt.Errorf("failed to ack zkmesbt package with oracle sequence %d, err=%s", sequence, sendErr.Error())
+	}
+	return nonce, nil
+}
+
+// waitForAcks waits until the ack txs are included, an error is returned if one of them reverted or is not included
+// within timeout.
+func waitForAcks(acker zkmeSBTAcker, txHashes []ethcommon.Hash, sequence uint64, timeout time.Duration) error {
+	if len(txHashes) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, txHash := range txHashes {
+		receipt, err := acker.WaitForEvmTxReceipt(ctx, txHash)
+		if err != nil {
+			return fmt.Errorf("failed to wait for zkmesbt ack with oracle sequence %d, txHash=%s, err=%s", sequence, txHash.Hex(), err.Error())
+		}
+		if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+			return fmt.Errorf("zkmesbt ack with oracle sequence %d reverted, txHash=%s", sequence, txHash.Hex())
+		}
+	}
+	return nil
+}
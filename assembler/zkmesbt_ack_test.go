@@ -0,0 +1,186 @@
+package assembler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+type sentAck struct {
+	user   ethcommon.Address
+	status uint8
+	nonce  uint64
+}
+
+// fakeAcker acks on an in-memory chain. The acks of the users in sendErrs fail to be sent, the receipts of the acks of
+// the users in reverted have a failed status, and the receipts are never returned if hang is set.
+type fakeAcker struct {
+	statuses map[ethcommon.Address]uint8
+	sendErrs map[ethcommon.Address]error
+	reverted map[ethcommon.Address]bool
+	hang     bool
+
+	sent   []sentAck
+	waited []ethcommon.Hash
+}
+
+func (f *fakeAcker) GetZkmeSBTCrossChainStatus(_ uint32, user ethcommon.Address) (uint8, error) {
+	return f.statuses[user], nil
+}
+
+func (f *fakeAcker) CallZkmeSBTAckMintedContract(_ uint32, user ethcommon.Address, status uint8, nonce uint64) (ethcommon.Hash, error) {
+	if err := f.sendErrs[user]; err != nil {
+		return ethcommon.Hash{}, err
+	}
+	f.sent = append(f.sent, sentAck{user: user, status: status, nonce: nonce})
+	return ethcommon.BytesToHash(user.Bytes()), nil
+}
+
+func (f *fakeAcker) WaitForEvmTxReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
+	f.waited = append(f.waited, txHash)
+	if f.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	user := ethcommon.BytesToAddress(txHash.Bytes())
+	if f.reverted[user] {
+		return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusFailed}, nil
+	}
+	f.statuses[user] = f.sent[len(f.waited)-1].status
+	return &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful}, nil
+}
+
+func TestAckZkmeSBT(t *testing.T) {
+	alice := ethcommon.HexToAddress("0x01")
+	bob := ethcommon.HexToAddress("0x02")
+	carol := ethcommon.HexToAddress("0x03")
+	const nonce = 10
+
+	tests := []struct {
+		name      string
+		ack       *ZkmeSBTAckPackageStruct
+		acker     *fakeAcker
+		timeout   time.Duration
+		sent      []sentAck
+		waited    int
+		nextNonce uint64
+		wantErr   bool
+	}{
+		{
+			name:      "every address is acked at consecutive nonces",
+			ack:       &ZkmeSBTAckPackageStruct{Toaddrs: []ethcommon.Address{alice, bob, carol}, Status: STATUS_SUCCESS},
+			acker:     &fakeAcker{},
+			sent:      []sentAck{{alice, TYPES_MIRROR_SUCCEED, 10}, {bob, TYPES_MIRROR_SUCCEED, 11}, {carol, TYPES_MIRROR_SUCCEED, 12}},
+			waited:    3,
+			nextNonce: 13,
+		},
+		{
+			name:      "a failed package acks the mirror failure",
+			ack:       &ZkmeSBTAckPackageStruct{Toaddrs: []ethcommon.Address{alice}, Status: 1},
+			acker:     &fakeAcker{},
+			sent:      []sentAck{{alice, TYPES_MIRROR_FAILED, 10}},
+			waited:    1,
+			nextNonce: 11,
+		},
+		{
+			name: "already acked addresses are skipped",
+			ack:  &ZkmeSBTAckPackageStruct{Toaddrs: []ethcommon.Address{alice, bob, carol}, Status: STATUS_SUCCESS},
+			acker: &fakeAcker{statuses: map[ethcommon.Address]uint8{
+				alice: TYPES_MIRROR_SUCCEED,
+				carol: TYPES_MIRROR_FAILED,
+			}},
+			sent:      []sentAck{{bob, TYPES_MIRROR_SUCCEED, 10}, {carol, TYPES_MIRROR_SUCCEED, 11}},
+			waited:    2,
+			nextNonce: 12,
+		},
+		{
+			name:      "nothing is sent nor waited for when every address is acked",
+			ack:       &ZkmeSBTAckPackageStruct{Toaddrs: []ethcommon.Address{alice}, Status: STATUS_SUCCESS},
+			acker:     &fakeAcker{statuses: map[ethcommon.Address]uint8{alice: TYPES_MIRROR_SUCCEED}},
+			nextNonce: 10,
+		},
+		{
+			name:      "duplicate addresses are acked once",
+			ack:       &ZkmeSBTAckPackageStruct{Toaddrs: []ethcommon.Address{alice, bob, alice, bob}, Status: STATUS_SUCCESS},
+			acker:     &fakeAcker{},
+			sent:      []sentAck{{alice, TYPES_MIRROR_SUCCEED, 10}, {bob, TYPES_MIRROR_SUCCEED, 11}},
+			waited:    2,
+			nextNonce: 12,
+		},
+		{
+			name:      "the nonce moves past the acks sent before a send failure and they are waited for",
+			ack:       &ZkmeSBTAckPackageStruct{Toaddrs: []ethcommon.Address{alice, bob, carol}, Status: STATUS_SUCCESS},
+			acker:     &fakeAcker{sendErrs: map[ethcommon.Address]error{bob: errors.New("send failed")}},
+			sent:      []sentAck{{alice, TYPES_MIRROR_SUCCEED, 10}},
+			waited:    1,
+			nextNonce: 11,
+			wantErr:   true,
+		},
+		{
+			name:      "a reverted ack fails the package",
+			ack:       &ZkmeSBTAckPackageStruct{Toaddrs: []ethcommon.Address{alice, bob, carol}, Status: STATUS_SUCCESS},
+			acker:     &fakeAcker{reverted: map[ethcommon.Address]bool{bob: true}},
+			sent:      []sentAck{{alice, TYPES_MIRROR_SUCCEED, 10}, {bob, TYPES_MIRROR_SUCCEED, 11}, {carol, TYPES_MIRROR_SUCCEED, 12}},
+			waited:    2,
+			nextNonce: 13,
+			wantErr:   true,
+		},
+		{
+			name:      "acks not included within the timeout fail the package",
+			ack:       &ZkmeSBTAckPackageStruct{Toaddrs: []ethcommon.Address{alice, bob}, Status: STATUS_SUCCESS},
+			acker:     &fakeAcker{hang: true},
+			timeout:   10 * time.Millisecond,
+			sent:      []sentAck{{alice, TYPES_MIRROR_SUCCEED, 10}, {bob, TYPES_MIRROR_SUCCEED, 11}},
+			waited:    1,
+			nextNonce: 12,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.acker.statuses == nil {
+				tt.acker.statuses = make(map[ethcommon.Address]uint8)
+			}
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = time.Second
+			}
+			nextNonce, err := ackZkmeSBT(tt.acker, 1, tt.ack, 5, nonce, timeout)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.nextNonce, nextNonce)
+			require.Equal(t, tt.sent, tt.acker.sent)
+			require.Len(t, tt.acker.waited, tt.waited)
+		})
+	}
+}
+
+func TestAckZkmeSBTRetry(t *testing.T) {
+	alice := ethcommon.HexToAddress("0x01")
+	bob := ethcommon.HexToAddress("0x02")
+	ack := &ZkmeSBTAckPackageStruct{Toaddrs: []ethcommon.Address{alice, bob}, Status: STATUS_SUCCESS}
+	acker := &fakeAcker{
+		statuses: make(map[ethcommon.Address]uint8),
+		sendErrs: map[ethcommon.Address]error{bob: errors.New("send failed")},
+	}
+
+	nonce, err := ackZkmeSBT(acker, 1, ack, 5, 10, time.Second)
+	require.Error(t, err)
+	require.Equal(t, uint64(11), nonce)
+
+	// the next attempt only acks the address which was not acked
+	acker.sendErrs = nil
+	acker.sent, acker.waited = nil, nil
+	nonce, err = ackZkmeSBT(acker, 1, ack, 5, nonce, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), nonce)
+	require.Equal(t, []sentAck{{bob, TYPES_MIRROR_SUCCEED, 11}}, acker.sent)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the relayer config",
+}
+
+var configCheckCmd = &cobra.Command{
+	Use:     "check",
+	Aliases: []string{"validate"},
+	Short:   "Check the config offline and report all errors and warnings",
+	Long: "Check the config offline and report all errors and warnings. Errors prevent the relayer from starting, " +
+		"warnings point out risky settings. The command fails if there is any error.",
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		cfg, err := readConfig()
+		if err != nil {
+			return err
+		}
+		issues := cfg.Check()
+		for _, i := range issues {
+			fmt.Printf("%-7s %s\n", i.Severity, i.Error())
+		}
+		errs := issues.Errors()
+		if len(errs) != 0 {
+			return fmt.Errorf("found %d errors and %d warnings", len(errs), len(issues)-len(errs))
+		}
+		fmt.Printf("config is valid with %d warnings\n", len(issues))
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configCheckCmd)
+}
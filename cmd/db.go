@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cometbft/cometbft/votepool"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+
+	"github.com/zkMeLabs/mechain-relayer/app"
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/db/dao"
+	"github.com/zkMeLabs/mechain-relayer/db/migration"
+	"github.com/zkMeLabs/mechain-relayer/listener"
+)
+
+const (
+	ChainMechain = "mechain"
+	ChainBSC     = "bsc"
+
+	flagChain  = "chain"
+	flagKeep   = "keep"
+	flagHeight = "height"
+	flagYes    = "yes"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Maintain the relayer DB",
+}
+
+var dbPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete the blocks, relay records and votes older than the latest kept blocks",
+	Long: "Delete the blocks, relay records and votes older than the latest kept blocks, the same way as the purge job " +
+		"of the listeners does. Records are only deleted if all of them below the threshold are processed, they are " +
+		"deleted in batches until none is left and the number of deleted records is printed.",
+	Args: cobra.NoArgs,
+	RunE: runDBPurge,
+}
+
+var dbRewindCmd = &cobra.Command{
+	Use:   "rewind",
+	Short: "Delete the blocks, relay records and votes above a height, so the listener resumes from the next height",
+	Args:  cobra.NoArgs,
+	RunE:  runDBRewind,
+}
+
+func init() {
+	dbPurgeCmd.Flags().String(flagChain, "", "chain to purge, mechain or bsc")
+	dbPurgeCmd.Flags().Int64(flagKeep, listener.NumOfHistoricalBlocks, "number of latest blocks to keep")
+	dbRewindCmd.Flags().String(flagChain, "", "chain to rewind, mechain or bsc")
+	dbRewindCmd.Flags().Uint64(flagHeight, 0, "height to rewind to, records above it are deleted")
+	dbRewindCmd.Flags().Bool(flagYes, false, "confirm the deletion")
+	for _, c := range []*cobra.Command{dbPurgeCmd, dbRewindCmd} {
+		if err := c.MarkFlagRequired(flagChain); err != nil {
+			panic(err)
+		}
+	}
+	if err := dbRewindCmd.MarkFlagRequired(flagHeight); err != nil {
+		panic(err)
+	}
+	dbCmd.AddCommand(dbPurgeCmd, dbRewindCmd)
+}
+
+// openDB opens the relayer DB for operational commands, which require an up-to-date schema.
+func openDB(cfg *config.Config) (*gorm.DB, error) {
+	relayerDB, err := app.OpenDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := migration.NewMigrator(relayerDB).HasPending()
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, fmt.Errorf("db schema is not up to date, run migrate up first")
+	}
+	return relayerDB, nil
+}
+
+func runDBPurge(cmd *cobra.Command, _ []string) error {
+	chain, err := cmd.Flags().GetString(flagChain)
+	if err != nil {
+		return err
+	}
+	keep, err := cmd.Flags().GetInt64(flagKeep)
+	if err != nil {
+		return err
+	}
+	if keep <= 0 {
+		return fmt.Errorf("--%s should be positive", flagKeep)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	daoManager, err := newDaoManager(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch chain {
+	case ChainMechain:
+		return purgeMechain(cfg, daoManager, keep)
+	case ChainBSC:
+		return purgeBSC(cfg, daoManager, keep)
+	default:
+		return fmt.Errorf("unknown chain %s, should be %s or %s", chain, ChainMechain, ChainBSC)
+	}
+}
+
+func purgeMechain(cfg *config.Config, daoManager *dao.DaoManager, keep int64) error {
+	latestBlock, err := daoManager.GreenfieldDao.GetLatestBlock()
+	if err != nil {
+		return err
+	}
+	threshHold := int64(latestBlock.Height) - keep
+	if threshHold <= 0 {
+		fmt.Println("nothing to purge")
+		return nil
+	}
+	exists, err := daoManager.GreenfieldDao.ExistsUnprocessedTransaction(threshHold)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("there are unprocessed transactions below height %d", threshHold)
+	}
+	blocks, err := daoManager.GreenfieldDao.DeleteBlocksBelowHeight(threshHold)
+	if err != nil {
+		return err
+	}
+	txs, err := deleteInBatches(func(limit int) (int64, error) {
+		return daoManager.GreenfieldDao.DeleteTransactionsBelowHeightWithLimit(threshHold, limit)
+	})
+	if err != nil {
+		return err
+	}
+	eventType := votepool.ToBscCrossChainEvent
+	if cfg.BSCConfig.IsOpCrossChain() {
+		eventType = votepool.ToOpCrossChainEvent
+	}
+	votes, err := deleteInBatches(func(limit int) (int64, error) {
+		return daoManager.VoteDao.DeleteVotesBelowHeightWithLimit(threshHold, uint32(eventType), limit)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("purged %d blocks, %d transactions and %d votes of Mechain below height %d\n", blocks, txs, votes, threshHold)
+	return nil
+}
+
+func purgeBSC(cfg *config.Config, daoManager *dao.DaoManager, keep int64) error {
+	latestBlock, err := daoManager.BSCDao.GetLatestBlock()
+	if err != nil {
+		return err
+	}
+	threshHold := int64(latestBlock.Height) - keep
+	if threshHold <= 0 {
+		fmt.Println("nothing to purge")
+		return nil
+	}
+	exists, err := daoManager.BSCDao.ExistsUnprocessedPackage(threshHold)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("there are unprocessed packages below height %d", threshHold)
+	}
+	blocks, err := daoManager.BSCDao.DeleteBlocksBelowHeight(threshHold)
+	if err != nil {
+		return err
+	}
+	pkgs, err := deleteInBatches(func(limit int) (int64, error) {
+		return daoManager.BSCDao.DeletePackagesBelowHeightWithLimit(threshHold, limit)
+	})
+	if err != nil {
+		return err
+	}
+	eventType := votepool.FromBscCrossChainEvent
+	if cfg.BSCConfig.IsOpCrossChain() {
+		eventType = votepool.FromOpCrossChainEvent
+	}
+	votes, err := deleteInBatches(func(limit int) (int64, error) {
+		return daoManager.VoteDao.DeleteVotesBelowHeightWithLimit(threshHold, uint32(eventType), limit)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("purged %d blocks, %d packages and %d votes of BSC below height %d\n", blocks, pkgs, votes, threshHold)
+	return nil
+}
+
+// deleteInBatches runs the delete with the deletion limit of the listeners until a batch deletes fewer records, so the
+// DB is not locked by one large delete, and returns the number of deleted records.
+func deleteInBatches(del func(limit int) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		n, err := del(listener.DeletionLimit)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < listener.DeletionLimit {
+			return total, nil
+		}
+	}
+}
+
+func runDBRewind(cmd *cobra.Command, _ []string) error {
+	chain, err := cmd.Flags().GetString(flagChain)
+	if err != nil {
+		return err
+	}
+	height, err := cmd.Flags().GetUint64(flagHeight)
+	if err != nil {
+		return err
+	}
+	yes, err := cmd.Flags().GetBool(flagYes)
+	if err != nil {
+		return err
+	}
+	if !yes {
+		return fmt.Errorf("rewinding deletes the %s records above height %d, pass --%s to confirm", chain, height, flagYes)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	daoManager, err := newDaoManager(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch chain {
+	case ChainMechain:
+		err = daoManager.GreenfieldDao.DeleteBlocksAndTransactionsAboveHeight(height)
+	case ChainBSC:
+		err = daoManager.BSCDao.DeleteBlocksAndPackagesAboveHeight(height)
+	default:
+		return fmt.Errorf("unknown chain %s, should be %s or %s", chain, ChainMechain, ChainBSC)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("rewound %s records to height %d\n", chain, height)
+	return nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zkMeLabs/mechain-relayer/db/model"
+)
+
+const flagSince = "since"
+
+var evidenceCmd = &cobra.Command{
+	Use:   "evidence",
+	Short: "Inspect the recorded vote equivocation evidences",
+}
+
+var evidenceExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the votes of validators which signed a conflicting event hash as JSON",
+	Long: "Export the votes of validators which signed an event hash other than the one computed by this relayer for " +
+		"the same channel and sequence. Each evidence holds the conflicting vote and the local vote over the expected " +
+		"event hash, both with their BLS public keys and signatures, so it can be attached to a governance or slashing " +
+		"proposal. The evidences are written to --file, or to stdout if it is empty.",
+	Args: cobra.NoArgs,
+	RunE: runEvidenceExport,
+}
+
+func init() {
+	evidenceExportCmd.Flags().String(flagFile, "", "path of the JSON file to write")
+	evidenceExportCmd.Flags().Int64(flagSince, 0, "only export the evidences recorded at or after this unix time")
+	evidenceCmd.AddCommand(evidenceExportCmd)
+}
+
+type voteEvidenceExport struct {
+	EventType            uint32 `json:"event_type"`
+	ChannelId            uint8  `json:"channel_id"`
+	Sequence             uint64 `json:"sequence"`
+	PubKey               string `json:"pub_key"`
+	ConflictingEventHash string `json:"conflicting_event_hash"`
+	ConflictingSignature string `json:"conflicting_signature"`
+	EventHash            string `json:"event_hash"`
+	LocalPubKey          string `json:"local_pub_key"`
+	LocalSignature       string `json:"local_signature"`
+	Height               int64  `json:"height"`
+	CreatedTime          int64  `json:"created_time"`
+}
+
+func runEvidenceExport(cmd *cobra.Command, _ []string) error {
+	file, err := cmd.Flags().GetString(flagFile)
+	if err != nil {
+		return err
+	}
+	since, err := cmd.Flags().GetInt64(flagSince)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	daoManager, err := newDaoManager(cfg)
+	if err != nil {
+		return err
+	}
+	evidences, err := daoManager.VoteDao.GetVoteEvidences(since)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		return writeEvidences(os.Stdout, evidences)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err = writeEvidences(f, evidences); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("exported %d vote evidences to %s\n", len(evidences), file)
+	return nil
+}
+
+func writeEvidences(w io.Writer, evidences []*model.VoteEvidence) error {
+	exported := make([]*voteEvidenceExport, 0, len(evidences))
+	for _, e := range evidences {
+		exported = append(exported, &voteEvidenceExport{
+			EventType:            e.EventType,
+			ChannelId:            e.ChannelId,
+			Sequence:             e.Sequence,
+			PubKey:               e.PubKey,
+			ConflictingEventHash: e.ConflictingEventHash,
+			ConflictingSignature: e.ConflictingSignature,
+			EventHash:            e.EventHash,
+			LocalPubKey:          e.LocalPubKey,
+			LocalSignature:       e.LocalSignature,
+			Height:               e.Height,
+			CreatedTime:          e.CreatedTime,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(exported)
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+
+	"github.com/0xPolygon/polygon-edge/bls"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/executor"
+	"github.com/zkMeLabs/mechain-relayer/keystore"
+)
+
+const (
+	flagKeystoreDir   = "keystore-dir"
+	flagPasswordFile  = "password-file"
+	flagLightKDF      = "light-kdf"
+	flagPrivateKey    = "private-key"
+	flagBSCPrivateKey = "bsc-private-key"
+	flagBlsPrivateKey = "bls-private-key"
+	flagCheck         = "check"
+
+	keystoreFileName    = "relayer_key.json"
+	blsKeystoreFileName = "relayer_bls_key.json"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Generate and inspect the relayer keys",
+}
+
+var keysShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the addresses and the BLS public key of the configured relayer keys",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		keys, err := executor.GetRelayerKeys(cfg)
+		if err != nil {
+			return err
+		}
+		printRelayerKeys(keys)
+		return nil
+	},
+}
+
+var keysGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate an ECDSA and a BLS private key and show the relayer identities derived from them",
+	Long: "Generate an ECDSA and a BLS private key and show the relayer identities derived from them. The private keys " +
+		"are printed in hex, or written as encrypted keystore files into --keystore-dir, which can be configured with " +
+		"key_type local_keystore. The keystore password is read from --password-file or the KEYSTORE_PASSWORD " +
+		"environment variable.",
+	Args: cobra.NoArgs,
+	RunE: runKeysGenerate,
+}
+
+var keysDeriveCmd = &cobra.Command{
+	Use:   "derive",
+	Short: "Show the relayer identities derived from hex encoded private keys",
+	Args:  cobra.NoArgs,
+	RunE:  runKeysDerive,
+}
+
+var keysInspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Show the identities of the configured relayer keys and check whether they are registered on both chains",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		keys, err := executor.GetRelayerKeys(cfg)
+		if err != nil {
+			return err
+		}
+		printRelayerKeys(keys)
+		return checkRelayerKeys(cfg, keys)
+	},
+}
+
+func init() {
+	keysGenerateCmd.Flags().String(flagKeystoreDir, "", "directory to write the keystore files to, the private keys are printed if empty")
+	keysGenerateCmd.Flags().String(flagPasswordFile, "", "file holding the keystore password")
+	keysGenerateCmd.Flags().Bool(flagLightKDF, false, "use cheaper scrypt parameters for the keystore files")
+	keysDeriveCmd.Flags().String(flagPrivateKey, "", "hex encoded Mechain private key")
+	keysDeriveCmd.Flags().String(flagBSCPrivateKey, "", "hex encoded BSC private key, the Mechain private key is used if empty")
+	keysDeriveCmd.Flags().String(flagBlsPrivateKey, "", "hex encoded BLS private key")
+	keysDeriveCmd.Flags().Bool(flagCheck, false, "check whether the keys are registered on both chains, which requires the config")
+	for _, f := range []string{flagPrivateKey, flagBlsPrivateKey} {
+		if err := keysDeriveCmd.MarkFlagRequired(f); err != nil {
+			panic(err)
+		}
+	}
+	keysCmd.AddCommand(keysShowCmd, keysGenerateCmd, keysDeriveCmd, keysInspectCmd)
+}
+
+func runKeysGenerate(cmd *cobra.Command, _ []string) error {
+	keystoreDir, err := cmd.Flags().GetString(flagKeystoreDir)
+	if err != nil {
+		return err
+	}
+	passwordFile, err := cmd.Flags().GetString(flagPasswordFile)
+	if err != nil {
+		return err
+	}
+	lightKDF, err := cmd.Flags().GetBool(flagLightKDF)
+	if err != nil {
+		return err
+	}
+
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate private key, err=%s", err.Error())
+	}
+	blsPrivKey, err := bls.GenerateBlsKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate bls private key, err=%s", err.Error())
+	}
+	blsSecret, err := blsPrivKey.Marshal()
+	if err != nil {
+		return err
+	}
+	blsSecret = ethcommon.LeftPadBytes(blsSecret, 32)
+	privKeyStr := hex.EncodeToString(crypto.FromECDSA(privKey))
+	blsPrivKeyStr := hex.EncodeToString(blsSecret)
+
+	keys, err := executor.DeriveRelayerKeys(privKeyStr, "", blsPrivKeyStr)
+	if err != nil {
+		return err
+	}
+	printRelayerKeys(keys)
+
+	if keystoreDir == "" {
+		fmt.Printf("Private key:     %s\n", privKeyStr)
+		fmt.Printf("BLS private key: %s\n", blsPrivKeyStr)
+		return nil
+	}
+	password, err := keystore.LoadPassword(passwordFile)
+	if err != nil {
+		return err
+	}
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if lightKDF {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+	return writeKeystores(keystoreDir, privKey, blsSecret, keys.BlsPubKey, password, scryptN, scryptP)
+}
+
+func writeKeystores(dir string, privKey *ecdsa.PrivateKey, blsSecret, blsPubKey []byte, password string, scryptN, scryptP int) error {
+	keyJSON, err := keystore.EncryptECDSAKey(privKey, password, scryptN, scryptP)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt private key, err=%s", err.Error())
+	}
+	blsKeyJSON, err := keystore.EncryptBLSKey(blsSecret, blsPubKey, password, scryptN, scryptP)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt bls private key, err=%s", err.Error())
+	}
+	// both keys are written or none, a failure never leaves half a key pair behind
+	files := map[string][]byte{keystoreFileName: keyJSON, blsKeystoreFileName: blsKeyJSON}
+	if err = keystore.WriteKeyFiles(dir, files); err != nil {
+		return err
+	}
+	for _, name := range []string{keystoreFileName, blsKeystoreFileName} {
+		fmt.Printf("wrote %s\n", filepath.Join(dir, name))
+	}
+	return nil
+}
+
+func runKeysDerive(cmd *cobra.Command, _ []string) error {
+	privKey, err := cmd.Flags().GetString(flagPrivateKey)
+	if err != nil {
+		return err
+	}
+	bscPrivKey, err := cmd.Flags().GetString(flagBSCPrivateKey)
+	if err != nil {
+		return err
+	}
+	blsPrivKey, err := cmd.Flags().GetString(flagBlsPrivateKey)
+	if err != nil {
+		return err
+	}
+	check, err := cmd.Flags().GetBool(flagCheck)
+	if err != nil {
+		return err
+	}
+	keys, err := executor.DeriveRelayerKeys(privKey, bscPrivKey, blsPrivKey)
+	if err != nil {
+		return err
+	}
+	printRelayerKeys(keys)
+	if !check {
+		return nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return checkRelayerKeys(cfg, keys)
+}
+
+func printRelayerKeys(keys *executor.RelayerKeys) {
+	fmt.Printf("Mechain address: %s\n", keys.GreenfieldAddress)
+	fmt.Printf("BSC address:     %s\n", keys.BSCAddress.Hex())
+	fmt.Printf("BLS public key:  %s\n", hex.EncodeToString(keys.BlsPubKey))
+}
+
+// checkRelayerKeys checks that the BLS public key is registered in the Mechain validator set and in the light client
+// contract on BSC, with the relayer addresses derived from the keys. An error is returned if any check fails.
+func checkRelayerKeys(cfg *config.Config, keys *executor.RelayerKeys) error {
+	failed := false
+
+	validator, err := executor.FindValidatorByBlsKey(cfg, keys.BlsPubKey)
+	if err != nil {
+		return fmt.Errorf("failed to query Mechain validators, err=%s", err.Error())
+	}
+	switch {
+	case validator == nil:
+		failed = true
+		fmt.Println("Mechain: BLS public key is not in the validator set")
+	case !keys.IsGreenfieldRelayer(validator.RelayerAddress):
+		failed = true
+		fmt.Printf("Mechain: BLS public key is registered with relayer address %s, not %s\n",
+			sdk.AccAddress(validator.RelayerAddress).String(), keys.GreenfieldAddress)
+	default:
+		fmt.Printf("Mechain: BLS public key is registered with validator %s\n", validator.Address.String())
+	}
+
+	relayer, err := executor.FindRelayerByBlsKey(cfg, keys.BlsPubKey)
+	if err != nil {
+		return fmt.Errorf("failed to query BSC light client relayers, err=%s", err.Error())
+	}
+	switch {
+	case relayer == nil:
+		failed = true
+		fmt.Println("BSC: BLS public key is not registered in the light client contract")
+	case relayer.RelayerAddress != keys.BSCAddress:
+		failed = true
+		fmt.Printf("BSC: BLS public key is registered with relayer address %s, not %s\n",
+			relayer.RelayerAddress.Hex(), keys.BSCAddress.Hex())
+	default:
+		fmt.Println("BSC: BLS public key is registered in the light client contract")
+	}
+
+	if failed {
+		return fmt.Errorf("relayer keys are not fully registered")
+	}
+	return nil
+}
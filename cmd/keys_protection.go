@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zkMeLabs/mechain-relayer/vote/protection"
+)
+
+const flagFile = "file"
+
+var keysProtectionCmd = &cobra.Command{
+	Use:   "protection",
+	Short: "Export or import the slashing protection journal of the vote signer",
+}
+
+var keysProtectionExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the signed votes to an interchange file, so they can be imported when moving the BLS key to another host",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		journal, file, err := openJournalAndFile(cmd)
+		if err != nil {
+			return err
+		}
+		defer journal.Close()
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		if err = journal.Export(f); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err = f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("exported signed votes to %s\n", file)
+		return nil
+	},
+}
+
+var keysProtectionImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the signed votes of an interchange file, nothing is imported if any vote conflicts with the journal",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		journal, file, err := openJournalAndFile(cmd)
+		if err != nil {
+			return err
+		}
+		defer journal.Close()
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		imported, err := journal.Import(f)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("imported %d signed votes from %s\n", imported, file)
+		return nil
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{keysProtectionExportCmd, keysProtectionImportCmd} {
+		c.Flags().String(flagFile, "", "path of the interchange file")
+		if err := c.MarkFlagRequired(flagFile); err != nil {
+			panic(err)
+		}
+	}
+	keysProtectionCmd.AddCommand(keysProtectionExportCmd, keysProtectionImportCmd)
+	keysCmd.AddCommand(keysProtectionCmd)
+}
+
+func openJournalAndFile(cmd *cobra.Command) (*protection.Journal, string, error) {
+	file, err := cmd.Flags().GetString(flagFile)
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	journal, err := protection.OpenJournal(cfg.VotePoolConfig.GetSlashingProtectionDBPath())
+	if err != nil {
+		return nil, "", err
+	}
+	return journal, file, nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zkMeLabs/mechain-relayer/app"
+)
+
+const (
+	flagAdminAddr = "admin-addr"
+
+	keysRotateTimeout = 5 * time.Minute
+)
+
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Ask the running relayer to reload its keys from the config and swap them in without a restart",
+	Long: "Ask the running relayer to reload its keys from the config and swap them in without a restart. The relay " +
+		"rounds are paused until the transactions sent with the old keys are included, the old keys stay in use if " +
+		"they are not included in time. The endpoint has to be enabled with key_rotation_enabled of admin_config, it is " +
+		"addressed by --admin-addr or key_rotation_addr, and authenticated with the token of key_rotation_token_file.",
+	Args: cobra.NoArgs,
+	RunE: runKeysRotate,
+}
+
+func init() {
+	keysRotateCmd.Flags().String(flagAdminAddr, "", "host:port of the key rotation endpoint of the running relayer")
+	keysCmd.AddCommand(keysRotateCmd)
+}
+
+func runKeysRotate(cmd *cobra.Command, _ []string) error {
+	addr, err := cmd.Flags().GetString(flagAdminAddr)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if addr == "" {
+		addr = cfg.AdminConfig.GetKeyRotationAddr()
+	}
+	token, err := app.ReadKeyRotationToken(cfg.AdminConfig.KeyRotationTokenFile)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", addr, app.KeyRotationPath), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := (&http.Client{Timeout: keysRotateTimeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("key rotation failed, status=%d, err=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	fmt.Println(strings.TrimSpace(string(body)))
+	return nil
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/zkMeLabs/mechain-relayer/app"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage the schema migrations of the relayer DB",
+	Long: "Manage the schema migrations of the relayer DB. Every migration checks the schema before changing it. MySQL " +
+		"commits schema changes implicitly, so a migration which failed halfway is not rolled back there, run the same " +
+		"command again to complete it.",
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return migrate(app.MigrateUp, 0)
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the latest applied migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		steps, err := cmd.Flags().GetInt(flagSteps)
+		if err != nil {
+			return err
+		}
+		return migrate(app.MigrateDown, steps)
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List migrations and whether they are applied",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return migrate(app.MigrateStatus, 0)
+	},
+}
+
+const flagSteps = "steps"
+
+func init() {
+	migrateDownCmd.Flags().Int(flagSteps, 1, "number of migrations to revert")
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd)
+}
+
+func migrate(command string, steps int) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return app.Migrate(cfg, command, steps)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zkMeLabs/mechain-relayer/types"
+)
+
+const (
+	flagChannel  = "channel"
+	flagSequence = "sequence"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Mark a relayed package as saved again, so it is voted and relayed once more",
+	Long: "Mark a relayed package as saved again and delete its votes, so it is voted and relayed once more by the " +
+		"running relayer. Mechain transactions are identified by channel and sequence, BSC packages by oracle sequence.",
+	Args: cobra.NoArgs,
+	RunE: runReplay,
+}
+
+func init() {
+	replayCmd.Flags().String(flagChain, "", "source chain of the package, mechain or bsc")
+	replayCmd.Flags().Uint8(flagChannel, 0, "channel id of the Mechain transaction")
+	replayCmd.Flags().Uint64(flagSequence, 0, "sequence of the Mechain transaction or oracle sequence of the BSC packages")
+	for _, f := range []string{flagChain, flagSequence} {
+		if err := replayCmd.MarkFlagRequired(f); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func runReplay(cmd *cobra.Command, _ []string) error {
+	chain, err := cmd.Flags().GetString(flagChain)
+	if err != nil {
+		return err
+	}
+	channel, err := cmd.Flags().GetUint8(flagChannel)
+	if err != nil {
+		return err
+	}
+	sequence, err := cmd.Flags().GetUint64(flagSequence)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	daoManager, err := newDaoManager(cfg)
+	if err != nil {
+		return err
+	}
+
+	var affected int64
+	switch chain {
+	case ChainMechain:
+		affected, err = daoManager.GreenfieldDao.ResetTransaction(types.ChannelId(channel), sequence)
+	case ChainBSC:
+		affected, err = daoManager.BSCDao.ResetPackagesByOracleSequence(sequence)
+	default:
+		return fmt.Errorf("unknown chain %s, should be %s or %s", chain, ChainMechain, ChainBSC)
+	}
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("no %s package found with sequence %d", chain, sequence)
+	}
+	fmt.Printf("reset %d %s records with sequence %d\n", affected, chain, sequence)
+	return nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "mechain-relayer",
+	Short: "Relayer between Mechain and evm-compatible chains",
+	// running without a subcommand starts the relayer for compatibility with existing deployments
+	RunE:          runRelayer,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.String(config.FlagConfigPath, "", "config file path")
+	flags.String(config.FlagConfigType, "", "config type, local or aws")
+	flags.String(config.FlagConfigAwsRegion, "", "aws region")
+	flags.String(config.FlagConfigAwsSecretKey, "", "aws secret key")
+	flags.String(config.FlagConfigPrivateKey, "", "relayer private key")
+	flags.String(config.FlagConfigBlsPrivateKey, "", "relayer bls private key")
+	flags.String(config.FlagConfigDbPass, "", "relayer db password")
+	flags.String(config.FlagConfigDbUsername, "", "relayer db username")
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(err)
+	}
+
+	rootCmd.AddCommand(
+		runCmd,
+		statusCmd,
+		replayCmd,
+		dbCmd,
+		keysCmd,
+		configCmd,
+		migrateCmd,
+		evidenceCmd,
+	)
+}
+
+// Execute runs the command given by the command line arguments.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// loadConfig reads the config and validates it.
+func loadConfig() (*config.Config, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// readConfig reads the config given by flags or env variables without validating it.
+func readConfig() (*config.Config, error) {
+	configType := viper.GetString(config.FlagConfigType)
+	if configType == "" {
+		configType = os.Getenv(config.ConfigType)
+	}
+	configFilePath := viper.GetString(config.FlagConfigPath)
+	if configFilePath == "" {
+		configFilePath = os.Getenv(config.ConfigFilePath)
+	}
+	return config.LoadConfig(&config.LoadOptions{
+		ConfigType:   configType,
+		FilePath:     configFilePath,
+		AWSSecretKey: viper.GetString(config.FlagConfigAwsSecretKey),
+		AWSRegion:    viper.GetString(config.FlagConfigAwsRegion),
+		Overrides: config.Overrides{
+			PrivateKey:    viper.GetString(config.FlagConfigPrivateKey),
+			BlsPrivateKey: viper.GetString(config.FlagConfigBlsPrivateKey),
+			DBUsername:    viper.GetString(config.FlagConfigDbUsername),
+			DBPassword:    viper.GetString(config.FlagConfigDbPass),
+		},
+	})
+}
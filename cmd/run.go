@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/zkMeLabs/mechain-relayer/app"
+	"github.com/zkMeLabs/mechain-relayer/logging"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Start the relayer",
+	Args:  cobra.NoArgs,
+	RunE:  runRelayer,
+}
+
+func runRelayer(_ *cobra.Command, _ []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	logging.InitLogger(&cfg.LogConfig)
+	for _, w := range cfg.Check().Warnings() {
+		logging.Logger.Warningf("config %s", w.Error())
+	}
+	app.NewApp(cfg, loadConfig).Start()
+	select {}
+}
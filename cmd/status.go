@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/db"
+	"github.com/zkMeLabs/mechain-relayer/db/dao"
+	"github.com/zkMeLabs/mechain-relayer/executor"
+	"github.com/zkMeLabs/mechain-relayer/metric"
+)
+
+const flagOffline = "offline"
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the relaying progress stored in the DB and the latest heights of both chains",
+	Args:  cobra.NoArgs,
+	RunE:  runStatus,
+}
+
+func init() {
+	statusCmd.Flags().Bool(flagOffline, false, "only show the progress stored in the DB, without querying the chains")
+}
+
+func runStatus(cmd *cobra.Command, _ []string) error {
+	offline, err := cmd.Flags().GetBool(flagOffline)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	daoManager, err := newDaoManager(cfg)
+	if err != nil {
+		return err
+	}
+
+	gnfdBlock, err := daoManager.GreenfieldDao.GetLatestBlock()
+	if err != nil {
+		return err
+	}
+	gnfdTxCounts, err := daoManager.GreenfieldDao.CountTransactionsByStatus()
+	if err != nil {
+		return err
+	}
+	bscBlock, err := daoManager.BSCDao.GetLatestBlock()
+	if err != nil {
+		return err
+	}
+	bscPkgCounts, err := daoManager.BSCDao.CountPackagesByStatus()
+	if err != nil {
+		return err
+	}
+	votes, err := daoManager.VoteDao.CountVotes()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Mechain (%s)\n", cfg.GreenfieldConfig.ChainIdString)
+	fmt.Fprintf(w, "  saved block height:\t%d\n", gnfdBlock.Height)
+	fmt.Fprintf(w, "  transactions:\t%s\n", formatStatusCounts(gnfdTxCounts))
+	fmt.Fprintf(w, "BSC (%d)\n", cfg.BSCConfig.ChainId)
+	fmt.Fprintf(w, "  saved block height:\t%d\n", bscBlock.Height)
+	fmt.Fprintf(w, "  packages:\t%s\n", formatStatusCounts(bscPkgCounts))
+	fmt.Fprintf(w, "votes:\t%d\n", votes)
+	if offline {
+		return w.Flush()
+	}
+
+	greenfieldExecutor := executor.NewGreenfieldExecutor(cfg)
+	bscExecutor := executor.NewBSCExecutor(cfg, metric.NewMetricService(cfg))
+	gnfdHeight, err := greenfieldExecutor.GetLatestBlockHeight()
+	if err != nil {
+		return fmt.Errorf("failed to get latest Mechain block height, err=%s", err.Error())
+	}
+	var bscHeight uint64
+	if cfg.BSCConfig.IsOpCrossChain() {
+		bscHeight, err = bscExecutor.GetLatestBlockHeightWithRetry()
+	} else {
+		bscHeight, err = bscExecutor.GetLatestFinalizedBlockHeightWithRetry()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get latest BSC block height, err=%s", err.Error())
+	}
+	lightClientHeight, err := bscExecutor.GetLightClientLatestHeight()
+	if err != nil {
+		return fmt.Errorf("failed to get light client height, err=%s", err.Error())
+	}
+	fmt.Fprintf(w, "Mechain chain height:\t%d (behind %d)\n", gnfdHeight, lag(gnfdHeight, gnfdBlock.Height))
+	fmt.Fprintf(w, "BSC chain height:\t%d (behind %d)\n", bscHeight, lag(bscHeight, bscBlock.Height))
+	fmt.Fprintf(w, "Mechain light client height on BSC:\t%d\n", lightClientHeight)
+	return w.Flush()
+}
+
+func newDaoManager(cfg *config.Config) (*dao.DaoManager, error) {
+	relayerDB, err := openDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return dao.NewDaoManager(dao.NewGreenfieldDao(relayerDB), dao.NewBSCDao(relayerDB), dao.NewVoteDao(relayerDB)), nil
+}
+
+func formatStatusCounts(counts map[db.TxStatus]int64) string {
+	return fmt.Sprintf("%s=%d %s=%d %s=%d %s=%d %s=%d %s=%d %s=%d",
+		db.Saved, counts[db.Saved],
+		db.SelfVoted, counts[db.SelfVoted],
+		db.AllVoted, counts[db.AllVoted],
+		db.Submitted, counts[db.Submitted],
+		db.Confirmed, counts[db.Confirmed],
+		db.Failed, counts[db.Failed],
+		db.Delivered, counts[db.Delivered])
+}
+
+func lag(chainHeight, savedHeight uint64) uint64 {
+	if chainHeight < savedHeight {
+		return 0
+	}
+	return chainHeight - savedHeight
+}
@@ -1,72 +1,224 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"strings"
+	"time"
+
+	"github.com/zkMeLabs/mechain-relayer/common"
 )
 
+// Config is the relayer config. The env tag of a section is the section name used by the environment overlay, see
+// ApplyEnvOverlay.
 type Config struct {
-	GreenfieldConfig GreenfieldConfig `json:"mechain-relayer"`
-	BSCConfig        BSCConfig        `json:"bsc_config"`
-	RelayConfig      RelayConfig      `json:"relay_config"`
-	VotePoolConfig   VotePoolConfig   `json:"vote_pool_config"`
-	LogConfig        LogConfig        `json:"log_config"`
-	AdminConfig      AdminConfig      `json:"admin_config"`
-	AlertConfig      AlertConfig      `json:"alert_config"`
-	DBConfig         DBConfig         `json:"db_config"`
+	GreenfieldConfig GreenfieldConfig `json:"mechain-relayer" env:"MECHAIN"`
+	BSCConfig        BSCConfig        `json:"bsc_config" env:"BSC"`
+	RelayConfig      RelayConfig      `json:"relay_config" env:"RELAY"`
+	VotePoolConfig   VotePoolConfig   `json:"vote_pool_config" env:"VOTE_POOL"`
+	LogConfig        LogConfig        `json:"log_config" env:"LOG"`
+	AdminConfig      AdminConfig      `json:"admin_config" env:"ADMIN"`
+	AlertConfig      AlertConfig      `json:"alert_config" env:"ALERT"`
+	DBConfig         DBConfig         `json:"db_config" env:"DB"`
+	SecretConfig     SecretConfig     `json:"secret_config" env:"SECRET"`
 }
 
 type AdminConfig struct {
-	Port uint16 `json:"port"`
+	Port                 uint16 `json:"port"`
+	WatchKeyFiles        bool   `json:"watch_key_files"`         // rotate the relayer keys when the keystore or secret files change
+	KeyRotationEnabled   bool   `json:"key_rotation_enabled"`    // serve the key rotation endpoint, off by default
+	KeyRotationAddr      string `json:"key_rotation_addr"`       // listen address of the key rotation endpoint, 127.0.0.1:8081 if empty
+	KeyRotationTokenFile string `json:"key_rotation_token_file"` // file holding the bearer token required by the key rotation endpoint
+}
+
+func (cfg *AdminConfig) Check() Issues {
+	is := make(Issues, 0)
+	if cfg.Port == 0 {
+		is.addError("port", "should be within (0, 65535]")
+	}
+	if cfg.KeyRotationEnabled && cfg.KeyRotationTokenFile == "" {
+		is.addError("key_rotation_token_file", "should not be empty when key_rotation_enabled is true")
+	}
+	return is
 }
 
-func (cfg *AdminConfig) Validate() {
-	if cfg.Port <= 0 || cfg.Port > 65535 {
-		panic("port should be within (0, 65535]")
+func (cfg *AdminConfig) GetKeyRotationAddr() string {
+	if cfg.KeyRotationAddr == "" {
+		return DefaultKeyRotationAddr
 	}
+	return cfg.KeyRotationAddr
 }
 
 type GreenfieldConfig struct {
-	KeyType            string   `json:"key_type"`
-	AWSRegion          string   `json:"aws_region"`
-	AWSSecretName      string   `json:"aws_secret_name"`
-	AWSBlsSecretName   string   `json:"aws_bls_secret_name"`
-	RPCAddrs           []string `json:"rpc_addrs"`
-	PrivateKey         string   `json:"private_key"`
-	BlsPrivateKey      string   `json:"bls_private_key"`
-	ChainId            uint64   `json:"chain_id"`
-	StartHeight        uint64   `json:"start_height"`
-	MonitorChannelList []uint8  `json:"monitor_channel_list"`
-	GasLimit           int64    `json:"gas_limit"`
-	FeeAmount          int64    `json:"fee_amount"`
-	ChainIdString      string   `json:"chain_id_string"`
-	UseWebsocket       bool     `json:"use_websocket"`
+	KeyType              string   `json:"key_type"`
+	AWSRegion            string   `json:"aws_region"`
+	AWSSecretName        string   `json:"aws_secret_name"`
+	AWSBlsSecretName     string   `json:"aws_bls_secret_name"`
+	SecretName           string   `json:"secret_name"`     // name of the key secret, aws_secret_name is used if empty
+	BlsSecretName        string   `json:"bls_secret_name"` // name of the BLS key secret, aws_bls_secret_name is used if empty
+	RPCAddrs             []string `json:"rpc_addrs"`
+	PrivateKey           string   `json:"private_key"`
+	BlsPrivateKey        string   `json:"bls_private_key"`
+	KeystoreFile         string   `json:"keystore_file"`          // geth keystore file of the key for key_type local_keystore
+	BlsKeystoreFile      string   `json:"bls_keystore_file"`      // EIP-2335 keystore file of the BLS key for key_type local_keystore
+	KeystorePasswordFile string   `json:"keystore_password_file"` // password of the keystore files, KEYSTORE_PASSWORD is read if empty
+	ChainId              uint64   `json:"chain_id"`
+	StartHeight          uint64   `json:"start_height"`
+	MonitorChannelList   []uint8  `json:"monitor_channel_list"`
+	GasLimit             int64    `json:"gas_limit"`
+	FeeAmount            int64    `json:"fee_amount"`
+	ChainIdString        string   `json:"chain_id_string"`
+	UseWebsocket         bool     `json:"use_websocket"`
+	// PrefetchSize is the max number of blocks fetched concurrently by the listener, the blocks are still saved in order
+	PrefetchSize uint64 `json:"prefetch_size"`
+	// FeeStrategy prices the txs sent to the Mechain EVM, legacy (default) or eip1559, like the fee_strategy of bsc_config.
+	// A legacy tx is priced at EvmGasPrice, or the gas price suggested by the node if 0, MaxGasPrice caps the gas price of
+	// legacy txs and the max fee per gas of eip1559 txs, 0 means no cap
+	FeeStrategy           string `json:"fee_strategy"`
+	EvmGasPrice           uint64 `json:"evm_gas_price"`
+	MaxGasPrice           uint64 `json:"max_gas_price"`
+	FeeHistoryBlocks      uint64 `json:"fee_history_blocks"`
+	PriorityFeePercentile uint64 `json:"priority_fee_percentile"`
+	MaxPriorityFeePerGas  uint64 `json:"max_priority_fee_per_gas"`
+	// SimulateClaim derives the gas limit of the claim txs from a simulation raised by GasLimitMarginPercent, and the fee
+	// from the min gas price of the chain. GasLimit and FeeAmount are used if the simulation fails
+	SimulateClaim         bool   `json:"simulate_claim"`
+	GasLimitMarginPercent uint64 `json:"gas_limit_margin_percent"`
+	// The in-turn relayer packs the claims of consecutive oracle sequences into one tx while their msgs stay within
+	// ClaimBatchMaxBytes and their gas limits within ClaimBatchMaxGas. Claims are sent one by one if ClaimBatchMaxBytes
+	// is 0, ClaimBatchMaxGas 0 means no gas budget
+	ClaimBatchMaxBytes uint64 `json:"claim_batch_max_bytes"`
+	ClaimBatchMaxGas   uint64 `json:"claim_batch_max_gas"`
 }
 
-func (cfg *GreenfieldConfig) Validate() {
-	if len(cfg.RPCAddrs) == 0 {
-		panic("provider address of Greenfield should not be empty")
+func (cfg *GreenfieldConfig) Check() Issues {
+	is := make(Issues, 0)
+	checkRPCAddrs(&is, cfg.RPCAddrs)
+	checkKeyType(&is, cfg.KeyType, KeyTypeLocalKeystore, KeyTypeVaultSecret, KeyTypeFileSecret, KeyTypeEnvSecret)
+	switch cfg.KeyType {
+	case KeyTypeAWSPrivateKey:
+		if cfg.AWSRegion == "" {
+			is.addError("aws_region", "should not be empty")
+		}
+		if cfg.AWSSecretName == "" {
+			is.addError("aws_secret_name", "should not be empty")
+		}
+		if cfg.AWSBlsSecretName == "" {
+			is.addError("aws_bls_secret_name", "should not be empty")
+		}
+	case KeyTypeLocalKeystore:
+		checkKeystoreFile(&is, "keystore_file", cfg.KeystoreFile)
+		checkKeystoreFile(&is, "bls_keystore_file", cfg.BlsKeystoreFile)
+		checkKeystorePassword(&is, cfg.KeystorePasswordFile)
+	case KeyTypeVaultSecret, KeyTypeFileSecret, KeyTypeEnvSecret:
+		if cfg.GetSecretName() == "" {
+			is.addError("secret_name", "should not be empty for key_type %s", cfg.KeyType)
+		}
+		if cfg.GetBlsSecretName() == "" {
+			is.addError("bls_secret_name", "should not be empty for key_type %s", cfg.KeyType)
+		}
+	default:
+		checkHexKey(&is, "private_key", cfg.PrivateKey)
+		checkHexKey(&is, "bls_private_key", cfg.BlsPrivateKey)
+	}
+	checkIgnoredValue(&is, "private_key", cfg.PrivateKey, cfg.KeyType)
+	checkIgnoredValue(&is, "bls_private_key", cfg.BlsPrivateKey, cfg.KeyType)
+	if cfg.ChainId == 0 {
+		is.addError("chain_id", "should be larger than 0")
+	}
+	checkFeeStrategy(&is, cfg.FeeStrategy)
+	checkFees(&is, "evm_gas_price", cfg.EvmGasPrice, cfg.MaxGasPrice, cfg.PriorityFeePercentile, cfg.MaxPriorityFeePerGas)
+	if cfg.ClaimBatchMaxBytes > MaxClaimBatchBytes {
+		is.addError("claim_batch_max_bytes", "should not be larger than %d", MaxClaimBatchBytes)
 	}
+	if cfg.ChainIdString == "" {
+		is.addError("chain_id_string", "should not be empty")
+	} else if cfg.ChainId != 0 && !strings.Contains(cfg.ChainIdString, fmt.Sprintf("_%d-", cfg.ChainId)) {
+		is.addWarning("chain_id_string", "%s does not match chain_id %d", cfg.ChainIdString, cfg.ChainId)
+	}
+	if cfg.StartHeight == 0 {
+		is.addError("start_height", "should be larger than 0")
+	}
+	if len(cfg.MonitorChannelList) == 0 {
+		is.addError("monitor_channel_list", "should not be empty")
+	}
+	seen := make(map[uint8]bool)
+	for _, c := range cfg.MonitorChannelList {
+		if seen[c] {
+			is.addWarning("monitor_channel_list", "channel %d is listed more than once", c)
+		}
+		seen[c] = true
+	}
+	if cfg.GasLimit <= 0 {
+		is.addError("gas_limit", "should be larger than 0")
+	}
+	if cfg.FeeAmount <= 0 {
+		is.addError("fee_amount", "should be larger than 0")
+	}
+	if cfg.GetPrefetchSize() > MaxGreenfieldPrefetchSize {
+		is.addWarning("prefetch_size", "%d is larger than %d, the RPC nodes may rate limit the listener", cfg.PrefetchSize, MaxGreenfieldPrefetchSize)
+	}
+	return is
+}
 
-	if cfg.KeyType == "" {
-		panic("key_type Greenfield should not be empty")
+func (cfg *GreenfieldConfig) GetSecretName() string {
+	if cfg.SecretName == "" {
+		return cfg.AWSSecretName
 	}
-	if cfg.KeyType != KeyTypeLocalPrivateKey && cfg.KeyType != KeyTypeAWSPrivateKey {
-		panic(fmt.Sprintf("key_type of Greenfield only supports %s and %s", KeyTypeLocalPrivateKey, KeyTypeAWSPrivateKey))
+	return cfg.SecretName
+}
+
+func (cfg *GreenfieldConfig) GetBlsSecretName() string {
+	if cfg.BlsSecretName == "" {
+		return cfg.AWSBlsSecretName
 	}
-	if cfg.KeyType == KeyTypeAWSPrivateKey && cfg.AWSRegion == "" {
-		panic("aws_region of Greenfield should not be empty")
+	return cfg.BlsSecretName
+}
+
+func (cfg *GreenfieldConfig) GetPrefetchSize() uint64 {
+	if cfg.PrefetchSize == 0 {
+		return DefaultGreenfieldPrefetchSize
+	}
+	return cfg.PrefetchSize
+}
+
+func (cfg *GreenfieldConfig) GetGasLimitMarginPercent() uint64 {
+	if cfg.GasLimitMarginPercent == 0 {
+		return DefaultGasLimitMarginPercent
+	}
+	return cfg.GasLimitMarginPercent
+}
+
+// IsClaimBatchEnabled reports whether the claims of consecutive oracle sequences are packed into one tx.
+func (cfg *GreenfieldConfig) IsClaimBatchEnabled() bool {
+	return cfg.ClaimBatchMaxBytes > 0
+}
+
+func (cfg *GreenfieldConfig) GetFeeStrategy() string {
+	if cfg.FeeStrategy == "" {
+		return FeeStrategyLegacy
 	}
-	if cfg.KeyType == KeyTypeAWSPrivateKey && cfg.AWSSecretName == "" {
-		panic("aws_secret_name of Greenfield should not be empty")
+	return cfg.FeeStrategy
+}
+
+func (cfg *GreenfieldConfig) GetFeeHistoryBlocks() uint64 {
+	if cfg.FeeHistoryBlocks == 0 {
+		return DefaultFeeHistoryBlocks
 	}
-	if cfg.KeyType != KeyTypeAWSPrivateKey && cfg.PrivateKey == "" {
-		panic("privateKey of Greenfield should not be empty")
+	return cfg.FeeHistoryBlocks
+}
+
+func (cfg *GreenfieldConfig) GetPriorityFeePercentile() uint64 {
+	if cfg.PriorityFeePercentile == 0 {
+		return DefaultPriorityFeePercentile
 	}
-	if cfg.GasLimit <= 0 || cfg.FeeAmount <= 0 {
-		panic("gas_limit and fee_amount should be larger than 0")
+	return cfg.PriorityFeePercentile
+}
+
+func (cfg *GreenfieldConfig) GetMaxPriorityFeePerGas() uint64 {
+	if cfg.MaxPriorityFeePerGas == 0 {
+		return cfg.MaxGasPrice
 	}
+	return cfg.MaxPriorityFeePerGas
 }
 
 type BSCConfig struct {
@@ -74,41 +226,207 @@ type BSCConfig struct {
 	KeyType                   string   `json:"key_type"`
 	AWSRegion                 string   `json:"aws_region"`
 	AWSSecretName             string   `json:"aws_secret_name"`
+	SecretName                string   `json:"secret_name"` // name of the key secret, aws_secret_name is used if empty
 	RPCAddrs                  []string `json:"rpc_addrs"`
 	PrivateKey                string   `json:"private_key"`
+	KeystoreFile              string   `json:"keystore_file"`          // geth keystore file of the key for key_type local_keystore
+	KeystorePasswordFile      string   `json:"keystore_password_file"` // password of keystore_file, KEYSTORE_PASSWORD is read if empty
+	RemoteSignerAddr          string   `json:"remote_signer_addr"`     // JSON-RPC url of the signer for key_type remote_signer
+	RemoteSignerAccount       string   `json:"remote_signer_account"`  // address the remote signer signs for
 	GasLimit                  uint64   `json:"gas_limit"`
 	GasPrice                  uint64   `json:"gas_price"`
 	NumberOfBlocksForFinality uint64   `json:"number_of_blocks_for_finality"`
 	StartHeight               uint64   `json:"start_height"`
 	ChainId                   uint64   `json:"chain_id"`
+	// CatchUpBatchSize is the max number of blocks scanned by one log query when the listener is catching up
+	CatchUpBatchSize uint64 `json:"catch_up_batch_size"`
+	// CatchUpThreshold is the number of blocks behind the head above which the listener scans logs over block ranges
+	// instead of polling block by block
+	CatchUpThreshold uint64 `json:"catch_up_threshold"`
+	// WebsocketAddr is an optional ws:// or wss:// endpoint, new heads and cross chain logs subscribed from it wake up
+	// the listener as soon as they arrive
+	WebsocketAddr string `json:"websocket_addr"`
+	// StuckTxTimeout is the time in second after which a claim tx not yet included is replaced by one with the same
+	// nonce and a gas price raised by GasPriceBumpPercent, at most up to MaxGasPrice
+	StuckTxTimeout      int64  `json:"stuck_tx_timeout"`
+	GasPriceBumpPercent uint64 `json:"gas_price_bump_percent"`
+	// MaxGasPrice caps the gas price of legacy txs and the max fee per gas of EIP-1559 txs, 0 means no cap
+	MaxGasPrice uint64 `json:"max_gas_price"`
+	// FeeStrategy prices the txs, legacy or eip1559, it defaults to eip1559 for the chains pricing on base fee plus tip.
+	// The priority fee of an eip1559 tx is the mean of the PriorityFeePercentile percentile of the priority fees paid in
+	// the last FeeHistoryBlocks blocks, at most MaxPriorityFeePerGas
+	FeeStrategy           string `json:"fee_strategy"`
+	FeeHistoryBlocks      uint64 `json:"fee_history_blocks"`
+	PriorityFeePercentile uint64 `json:"priority_fee_percentile"`
+	MaxPriorityFeePerGas  uint64 `json:"max_priority_fee_per_gas"`
+	// The gas limit of a tx is its estimated gas raised by GasLimitMarginPercent, within [MinGasLimit, MaxGasLimit].
+	// GasLimit is used only if the estimation fails
+	GasLimitMarginPercent uint64 `json:"gas_limit_margin_percent"`
+	MinGasLimit           uint64 `json:"min_gas_limit"`
+	MaxGasLimit           uint64 `json:"max_gas_limit"`
+}
+
+func (cfg *BSCConfig) Check() Issues {
+	is := make(Issues, 0)
+	checkRPCAddrs(&is, cfg.RPCAddrs)
+	checkKeyType(&is, cfg.KeyType, KeyTypeLocalKeystore, KeyTypeRemoteSigner, KeyTypeVaultSecret, KeyTypeFileSecret, KeyTypeEnvSecret)
+	switch cfg.KeyType {
+	case KeyTypeAWSPrivateKey:
+		if cfg.AWSRegion == "" {
+			is.addError("aws_region", "should not be empty")
+		}
+		if cfg.AWSSecretName == "" {
+			is.addError("aws_secret_name", "should not be empty")
+		}
+	case KeyTypeLocalKeystore:
+		checkKeystoreFile(&is, "keystore_file", cfg.KeystoreFile)
+		checkKeystorePassword(&is, cfg.KeystorePasswordFile)
+	case KeyTypeVaultSecret, KeyTypeFileSecret, KeyTypeEnvSecret:
+		if cfg.GetSecretName() == "" {
+			is.addError("secret_name", "should not be empty for key_type %s", cfg.KeyType)
+		}
+	case KeyTypeRemoteSigner:
+		if !strings.Contains(cfg.RemoteSignerAddr, "://") {
+			is.addError("remote_signer_addr", "%q should be an url with scheme", cfg.RemoteSignerAddr)
+		}
+		checkHexAddr(&is, "remote_signer_account", cfg.RemoteSignerAccount)
+	default:
+		checkHexKey(&is, "private_key", cfg.PrivateKey)
+	}
+	checkIgnoredValue(&is, "private_key", cfg.PrivateKey, cfg.KeyType)
+	if cfg.GasLimit == 0 {
+		is.addError("gas_limit", "should be larger than 0")
+	}
+	if cfg.GasPrice == 0 {
+		is.addWarning("gas_price", "is 0, the gas price suggested by the rpc node is used")
+	}
+	if cfg.NumberOfBlocksForFinality < 1 || cfg.NumberOfBlocksForFinality > 21 {
+		is.addError("number_of_blocks_for_finality", "should be within [1, 21]")
+	}
+	if cfg.StartHeight == 0 {
+		is.addWarning("start_height", "is 0, the listener scans the chain from genesis when the DB is empty")
+	}
+	if cfg.WebsocketAddr != "" && !strings.HasPrefix(cfg.WebsocketAddr, "ws://") && !strings.HasPrefix(cfg.WebsocketAddr, "wss://") {
+		is.addError("websocket_addr", "%q should be a ws:// or wss:// url", cfg.WebsocketAddr)
+	}
+	if cfg.StuckTxTimeout < 0 {
+		is.addError("stuck_tx_timeout", "should not be negative")
+	}
+	if cfg.GasPriceBumpPercent != 0 && cfg.GasPriceBumpPercent < MinGasPriceBumpPercent {
+		is.addError("gas_price_bump_percent", "should be at least %d, nodes reject replacements with a lower bump", MinGasPriceBumpPercent)
+	}
+	if cfg.GetMinGasLimit() > cfg.GetMaxGasLimit() {
+		is.addError("min_gas_limit", "should not be larger than max_gas_limit")
+	}
+	checkFeeStrategy(&is, cfg.FeeStrategy)
+	checkFees(&is, "gas_price", cfg.GasPrice, cfg.MaxGasPrice, cfg.PriorityFeePercentile, cfg.MaxPriorityFeePerGas)
+	if cfg.GetCatchUpThreshold() < cfg.NumberOfBlocksForFinality {
+		is.addWarning("catch_up_threshold", "is less than number_of_blocks_for_finality, reorgs near the head may not be detected")
+	}
+	if cfg.ChainId == 0 {
+		is.addError("chain_id", "should be larger than 0")
+	} else if name, ok := KnownChainIds[cfg.ChainId]; !ok {
+		is.addWarning("chain_id", "%d is not a known chain id", cfg.ChainId)
+	} else {
+		isBSC := cfg.ChainId == common.BSCChainId || cfg.ChainId == common.BSCTestnetChainId
+		if isBSC && cfg.OpBNB {
+			is.addWarning("op_bnb", "is true for %s, the latest instead of the finalized block is relayed", name)
+		}
+		if !isBSC && !cfg.OpBNB {
+			is.addWarning("op_bnb", "is false for %s, which may not support querying finalized blocks", name)
+		}
+	}
+	return is
+}
+
+func (cfg *BSCConfig) GetSecretName() string {
+	if cfg.SecretName == "" {
+		return cfg.AWSSecretName
+	}
+	return cfg.SecretName
 }
 
-func (cfg *BSCConfig) Validate() {
-	if len(cfg.RPCAddrs) == 0 {
-		panic("provider address of BNB Smart Chain should not be empty")
+func (cfg *BSCConfig) GetCatchUpBatchSize() uint64 {
+	if cfg.CatchUpBatchSize == 0 {
+		return DefaultBSCCatchUpBatchSize
 	}
+	return cfg.CatchUpBatchSize
+}
 
-	if cfg.KeyType == "" {
-		panic("key_type BNB Smart Chain should not be empty")
+func (cfg *BSCConfig) GetCatchUpThreshold() uint64 {
+	if cfg.CatchUpThreshold == 0 {
+		return DefaultBSCCatchUpThreshold
 	}
-	if cfg.KeyType != KeyTypeLocalPrivateKey && cfg.KeyType != KeyTypeAWSPrivateKey {
-		panic(fmt.Sprintf("key_type of BNB Smart Chain only supports %s and %s", KeyTypeLocalPrivateKey, KeyTypeAWSPrivateKey))
+	return cfg.CatchUpThreshold
+}
+
+func (cfg *BSCConfig) GetStuckTxTimeout() time.Duration {
+	if cfg.StuckTxTimeout == 0 {
+		return DefaultBSCStuckTxTimeout
 	}
-	if cfg.KeyType == KeyTypeAWSPrivateKey && cfg.AWSRegion == "" {
-		panic("aws_region of BNB Smart Chain should not be empty")
+	return time.Duration(cfg.StuckTxTimeout) * time.Second
+}
+
+func (cfg *BSCConfig) GetGasPriceBumpPercent() uint64 {
+	if cfg.GasPriceBumpPercent == 0 {
+		return DefaultGasPriceBumpPercent
 	}
-	if cfg.KeyType == KeyTypeAWSPrivateKey && cfg.AWSSecretName == "" {
-		panic("aws_secret_name of BNB Smart Chain should not be empty")
+	return cfg.GasPriceBumpPercent
+}
+
+// GetFeeStrategy returns the fee strategy, eip1559 for the chains pricing on base fee plus tip if not set.
+func (cfg *BSCConfig) GetFeeStrategy() string {
+	if cfg.FeeStrategy != "" {
+		return cfg.FeeStrategy
 	}
-	if cfg.KeyType != KeyTypeAWSPrivateKey && cfg.PrivateKey == "" {
-		panic("privateKey of BNB Smart Chain should not be empty")
+	switch cfg.ChainId {
+	case common.ArbitrumChainId, common.OptimismChainId, common.LineaChainId, common.ScrollChainId, common.MantleChainId:
+		return FeeStrategyEIP1559
+	default:
+		return FeeStrategyLegacy
 	}
-	if cfg.GasLimit == 0 {
-		panic("gas_limit of BNB Smart Chain should be larger than 0")
+}
+
+func (cfg *BSCConfig) GetFeeHistoryBlocks() uint64 {
+	if cfg.FeeHistoryBlocks == 0 {
+		return DefaultFeeHistoryBlocks
 	}
-	if cfg.NumberOfBlocksForFinality < 1 || cfg.NumberOfBlocksForFinality > 21 {
-		panic("NumberOfBlocksForFinality should be [1, 21]")
+	return cfg.FeeHistoryBlocks
+}
+
+func (cfg *BSCConfig) GetPriorityFeePercentile() uint64 {
+	if cfg.PriorityFeePercentile == 0 {
+		return DefaultPriorityFeePercentile
 	}
+	return cfg.PriorityFeePercentile
+}
+
+func (cfg *BSCConfig) GetMaxPriorityFeePerGas() uint64 {
+	if cfg.MaxPriorityFeePerGas == 0 {
+		return cfg.MaxGasPrice
+	}
+	return cfg.MaxPriorityFeePerGas
+}
+
+func (cfg *BSCConfig) GetGasLimitMarginPercent() uint64 {
+	if cfg.GasLimitMarginPercent == 0 {
+		return DefaultGasLimitMarginPercent
+	}
+	return cfg.GasLimitMarginPercent
+}
+
+func (cfg *BSCConfig) GetMinGasLimit() uint64 {
+	if cfg.MinGasLimit == 0 {
+		return DefaultBSCMinGasLimit
+	}
+	return cfg.MinGasLimit
+}
+
+func (cfg *BSCConfig) GetMaxGasLimit() uint64 {
+	if cfg.MaxGasLimit == 0 {
+		return DefaultBSCMaxGasLimit
+	}
+	return cfg.MaxGasLimit
 }
 
 func (cfg *BSCConfig) IsOpCrossChain() bool {
@@ -124,39 +442,78 @@ type RelayConfig struct {
 	GreenfieldLightClientContractAddr   string `json:"greenfield_light_client_contract_addr"`
 	RelayerHubContractAddr              string `json:"relayer_hub_contract_addr"`
 	SrcZkmeSBTContractAddr              string `json:"src_zkmesbt_contract_addr"`
+	// AckTxTimeout is the time in second the AckMinted txs of a zkmesbt ack package are waited for before the claim of
+	// its oracle sequence, the relay round of the in-turn relayer is blocked meanwhile
+	AckTxTimeout int64 `json:"ack_tx_timeout"`
 }
 
-func (cfg *RelayConfig) Validate() {
+func (cfg *RelayConfig) Check() Issues {
+	is := make(Issues, 0)
 	if cfg.BSCToGreenfieldInturnRelayerTimeout <= 0 {
-		panic("bsc_to_greenfield_inturn_relayer_timeout should be larger than 0")
+		is.addError("bsc_to_greenfield_inturn_relayer_timeout", "should be larger than 0")
 	}
 	if cfg.GreenfieldToBSCInturnRelayerTimeout <= 0 {
-		panic("greenfield_to_bsc_inturn_relayer_timeout should be larger than 0")
+		is.addError("greenfield_to_bsc_inturn_relayer_timeout", "should be larger than 0")
 	}
 	if cfg.GreenfieldSequenceUpdateLatency <= 0 {
-		panic("greenfield_sequence_update_latency should be larger than 0")
+		is.addError("greenfield_sequence_update_latency", "should be larger than 0")
 	}
 	if cfg.BSCSequenceUpdateLatency <= 0 {
-		panic("bsc_sequence_update_latency should be larger than 0")
+		is.addError("bsc_sequence_update_latency", "should be larger than 0")
+	}
+	if cfg.GreenfieldToBSCInturnRelayerTimeout > 0 && cfg.GreenfieldSequenceUpdateLatency >= cfg.GreenfieldToBSCInturnRelayerTimeout {
+		is.addWarning("greenfield_sequence_update_latency", "is not less than greenfield_to_bsc_inturn_relayer_timeout, "+
+			"out-turn relayers may relay packages the in-turn relayer has already delivered")
+	}
+	if cfg.BSCToGreenfieldInturnRelayerTimeout > 0 && cfg.BSCSequenceUpdateLatency >= cfg.BSCToGreenfieldInturnRelayerTimeout {
+		is.addWarning("bsc_sequence_update_latency", "is not less than bsc_to_greenfield_inturn_relayer_timeout, "+
+			"out-turn relayers may relay packages the in-turn relayer has already delivered")
+	}
+	checkHexAddr(&is, "cross_chain_contract_addr", cfg.CrossChainContractAddr)
+	checkHexAddr(&is, "greenfield_light_client_contract_addr", cfg.GreenfieldLightClientContractAddr)
+	checkHexAddr(&is, "relayer_hub_contract_addr", cfg.RelayerHubContractAddr)
+	if cfg.SrcZkmeSBTContractAddr != "" {
+		checkHexAddr(&is, "src_zkmesbt_contract_addr", cfg.SrcZkmeSBTContractAddr)
+	}
+	if cfg.AckTxTimeout < 0 {
+		is.addError("ack_tx_timeout", "should not be negative")
+	}
+	return is
+}
+
+func (cfg *RelayConfig) GetAckTxTimeout() time.Duration {
+	if cfg.AckTxTimeout == 0 {
+		return DefaultAckTxTimeout
 	}
+	return time.Duration(cfg.AckTxTimeout) * time.Second
 }
 
 type VotePoolConfig struct {
-	BroadcastIntervalInMillisecond int64 `json:"broadcast_interval_in_millisecond"`
-	VotesBatchMaxSizePerInterval   int64 `json:"votes_batch_max_size_per_interval"`
-	QueryIntervalInMillisecond     int64 `json:"query_interval_in_millisecond"`
+	BroadcastIntervalInMillisecond int64  `json:"broadcast_interval_in_millisecond"`
+	VotesBatchMaxSizePerInterval   int64  `json:"votes_batch_max_size_per_interval"`
+	QueryIntervalInMillisecond     int64  `json:"query_interval_in_millisecond"`
+	SlashingProtectionDBPath       string `json:"slashing_protection_db_path"` // sqlite file of the signed votes, slashing_protection.db by default
 }
 
-func (cfg *VotePoolConfig) Validate() {
+func (cfg *VotePoolConfig) Check() Issues {
+	is := make(Issues, 0)
 	if cfg.BroadcastIntervalInMillisecond <= 0 {
-		panic("broadcast_interval_in_millisecond should be larger than 0")
+		is.addError("broadcast_interval_in_millisecond", "should be larger than 0")
 	}
 	if cfg.VotesBatchMaxSizePerInterval <= 0 {
-		panic("votes_batch_max_size_per_interval should be larger than 0")
+		is.addError("votes_batch_max_size_per_interval", "should be larger than 0")
 	}
 	if cfg.QueryIntervalInMillisecond <= 0 {
-		panic("query_interval_in_millisecond should be larger than 0")
+		is.addError("query_interval_in_millisecond", "should be larger than 0")
+	}
+	return is
+}
+
+func (cfg *VotePoolConfig) GetSlashingProtectionDBPath() string {
+	if cfg.SlashingProtectionDBPath == "" {
+		return DefaultSlashingProtectionDBPath
 	}
+	return cfg.SlashingProtectionDBPath
 }
 
 type LogConfig struct {
@@ -170,18 +527,28 @@ type LogConfig struct {
 	Compress                     bool   `json:"compress"`
 }
 
-func (cfg *LogConfig) Validate() {
+func (cfg *LogConfig) Check() Issues {
+	is := make(Issues, 0)
+	if cfg.Level == "" {
+		is.addWarning("level", "is empty, only CRITICAL logs are written")
+	} else if !LogLevels[cfg.Level] {
+		is.addError("level", "%q is not one of CRITICAL, ERROR, WARNING, NOTICE, INFO and DEBUG", cfg.Level)
+	}
 	if cfg.UseFileLogger {
 		if cfg.Filename == "" {
-			panic("filename should not be empty if use file logger")
+			is.addError("filename", "should not be empty if use file logger")
 		}
 		if cfg.MaxFileSizeInMB <= 0 {
-			panic("max_file_size_in_mb should be larger than 0 if use file logger")
+			is.addError("max_file_size_in_mb", "should be larger than 0 if use file logger")
 		}
 		if cfg.MaxBackupsOfLogFiles <= 0 {
-			panic("max_backups_off_log_files should be larger than 0 if use file logger")
+			is.addError("max_backups_of_log_files", "should be larger than 0 if use file logger")
 		}
 	}
+	if !cfg.UseConsoleLogger && !cfg.UseFileLogger {
+		is.addWarning("use_console_logger", "neither console nor file logger is used, nothing is logged")
+	}
+	return is
 }
 
 type AlertConfig struct {
@@ -190,59 +557,148 @@ type AlertConfig struct {
 	TelegramChatId string `json:"telegram_chat_id"`
 }
 
+func (cfg *AlertConfig) Check() Issues {
+	is := make(Issues, 0)
+	if cfg.TelegramBotId == "" || cfg.TelegramChatId == "" {
+		is.addWarning("telegram_bot_id", "telegram_bot_id and telegram_chat_id are required to send alerts")
+	}
+	return is
+}
+
 type DBConfig struct {
 	Dialect       string `json:"dialect"`
 	KeyType       string `json:"key_type"`
 	AWSRegion     string `json:"aws_region"`
 	AWSSecretName string `json:"aws_secret_name"`
+	SecretName    string `json:"secret_name"` // name of the password secret, aws_secret_name is used if empty
 	Password      string `json:"password"`
 	Username      string `json:"username"`
 	Url           string `json:"url"`
 	MaxIdleConns  int    `json:"max_idle_conns"`
 	MaxOpenConns  int    `json:"max_open_conns"`
+	// SkipMigration disables applying pending schema migrations on startup, the relayer refuses to start if any
+	// migration is pending, run the migrate up subcommand instead.
+	SkipMigration bool `json:"skip_migration"`
 }
 
-func (cfg *DBConfig) Validate() {
-	if cfg.Dialect != DBDialectMysql && cfg.Dialect != DBDialectSqlite3 {
-		panic(fmt.Sprintf("only %s and %s supported", DBDialectMysql, DBDialectSqlite3))
+func (cfg *DBConfig) GetSecretName() string {
+	if cfg.SecretName == "" {
+		return cfg.AWSSecretName
 	}
-	if cfg.Dialect == DBDialectMysql && (cfg.Username == "" || cfg.Url == "") {
-		panic("db config is not correct, missing username and/or url")
+	return cfg.SecretName
+}
+
+func (cfg *DBConfig) Check() Issues {
+	is := make(Issues, 0)
+	switch cfg.Dialect {
+	case DBDialectMysql:
+		if cfg.Username == "" {
+			is.addError("username", "should not be empty for %s", DBDialectMysql)
+		}
+		if cfg.Url == "" {
+			is.addError("url", "should not be empty")
+		}
+		if cfg.KeyType == KeyTypeAWSPrivateKey && (cfg.AWSRegion == "" || cfg.AWSSecretName == "") {
+			is.addError("aws_secret_name", "aws_region and aws_secret_name should not be empty for key_type %s", KeyTypeAWSPrivateKey)
+		}
+		if IsSecretKeyType(cfg.KeyType) && cfg.KeyType != KeyTypeAWSPrivateKey && cfg.GetSecretName() == "" {
+			is.addError("secret_name", "should not be empty for key_type %s", cfg.KeyType)
+		}
+		checkIgnoredValue(&is, "password", cfg.Password, cfg.KeyType)
+	case DBDialectSqlite3:
+		if cfg.Url == "" {
+			is.addError("url", "should be the sqlite db file path")
+		}
+	default:
+		is.addError("dialect", "only %s and %s supported", DBDialectMysql, DBDialectSqlite3)
+	}
+	if cfg.MaxIdleConns <= 0 {
+		is.addError("max_idle_conns", "should be larger than 0")
+	}
+	if cfg.MaxOpenConns <= 0 {
+		is.addError("max_open_conns", "should be larger than 0")
+	}
+	if cfg.MaxIdleConns > cfg.MaxOpenConns {
+		is.addWarning("max_idle_conns", "is larger than max_open_conns")
 	}
-	if cfg.MaxIdleConns == 0 || cfg.MaxOpenConns == 0 {
-		panic("db connections is not correct")
+	return is
+}
+
+// Check checks all sections of the config and returns every problem found.
+func (cfg *Config) Check() Issues {
+	is := make(Issues, 0)
+	is = append(is, cfg.GreenfieldConfig.Check().withSection("mechain-relayer")...)
+	is = append(is, cfg.BSCConfig.Check().withSection("bsc_config")...)
+	is = append(is, cfg.RelayConfig.Check().withSection("relay_config")...)
+	is = append(is, cfg.VotePoolConfig.Check().withSection("vote_pool_config")...)
+	is = append(is, cfg.LogConfig.Check().withSection("log_config")...)
+	is = append(is, cfg.AdminConfig.Check().withSection("admin_config")...)
+	is = append(is, cfg.AlertConfig.Check().withSection("alert_config")...)
+	is = append(is, cfg.DBConfig.Check().withSection("db_config")...)
+	is = append(is, cfg.SecretConfig.Check().withSection("secret_config")...)
+	if cfg.SecretConfig.VaultAddr == "" && (cfg.GreenfieldConfig.KeyType == KeyTypeVaultSecret ||
+		cfg.BSCConfig.KeyType == KeyTypeVaultSecret || cfg.DBConfig.KeyType == KeyTypeVaultSecret) {
+		is.addError("secret_config.vault_addr", "should not be empty if any key_type is %s", KeyTypeVaultSecret)
+	}
+	for _, c := range cfg.GreenfieldConfig.MonitorChannelList {
+		if c == uint8(common.ZkmeSBTChannelId) && cfg.RelayConfig.SrcZkmeSBTContractAddr == "" {
+			is.addError("relay_config.src_zkmesbt_contract_addr", "should not be empty if channel %d is monitored", c)
+		}
 	}
+	return is
 }
 
-func (cfg *Config) Validate() {
-	cfg.AdminConfig.Validate()
-	cfg.LogConfig.Validate()
-	cfg.GreenfieldConfig.Validate()
-	cfg.BSCConfig.Validate()
-	cfg.RelayConfig.Validate()
-	cfg.VotePoolConfig.Validate()
-	cfg.DBConfig.Validate()
+// Validate returns an error listing all errors in the config, warnings are ignored.
+func (cfg *Config) Validate() error {
+	return cfg.Check().Err()
 }
 
-func ParseConfigFromJson(content string) *Config {
-	var config Config
-	if err := json.Unmarshal([]byte(content), &config); err != nil {
-		panic(err)
+func ParseConfigFromJson(content string) (*Config, error) {
+	config, err := DecodeConfigFromJson([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	if err = config.Validate(); err != nil {
+		return nil, err
 	}
-	config.Validate()
-	return &config
+	return config, nil
 }
 
-func ParseConfigFromFile(filePath string) *Config {
-	bz, err := os.ReadFile(filePath)
+func ParseConfigFromFile(filePath string) (*Config, error) {
+	config, err := DecodeConfigFromFile(filePath)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
 
-	var config Config
-	if err := json.Unmarshal(bz, &config); err != nil {
-		panic(err)
+// KeyFiles returns the files the relayer keys are read from, which are the keystore files, their password files and the
+// secret files of key_type file_secret.
+func (cfg *Config) KeyFiles() []string {
+	files := make([]string, 0)
+	secrets := &FileSecretProvider{Dir: cfg.SecretConfig.SecretDir}
+	gnfdCfg := &cfg.GreenfieldConfig
+	switch gnfdCfg.KeyType {
+	case KeyTypeLocalKeystore:
+		files = append(files, gnfdCfg.KeystoreFile, gnfdCfg.BlsKeystoreFile, gnfdCfg.KeystorePasswordFile)
+	case KeyTypeFileSecret:
+		files = append(files, secrets.Path(gnfdCfg.GetSecretName()), secrets.Path(gnfdCfg.GetBlsSecretName()))
+	}
+	bscCfg := &cfg.BSCConfig
+	switch bscCfg.KeyType {
+	case KeyTypeLocalKeystore:
+		files = append(files, bscCfg.KeystoreFile, bscCfg.KeystorePasswordFile)
+	case KeyTypeFileSecret:
+		files = append(files, secrets.Path(bscCfg.GetSecretName()))
+	}
+	nonEmpty := files[:0]
+	for _, f := range files {
+		if f != "" {
+			nonEmpty = append(nonEmpty, f)
+		}
 	}
-	config.Validate()
-	return &config
+	return nonEmpty
 }
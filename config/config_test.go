@@ -0,0 +1,97 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShippedConfigsAreValid(t *testing.T) {
+	files, err := filepath.Glob("*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, f := range files {
+		cfg, err := DecodeConfigFromFile(f)
+		require.NoError(t, err, f)
+		require.Empty(t, cfg.Check().Errors(), f)
+	}
+}
+
+func TestCheckReportsAllIssues(t *testing.T) {
+	cfg, err := DecodeConfigFromFile("config.json")
+	require.NoError(t, err)
+	cfg.GreenfieldConfig.MonitorChannelList = nil
+	cfg.GreenfieldConfig.BlsPrivateKey = "0x1234"
+	cfg.BSCConfig.ChainId = 12345
+	cfg.RelayConfig.CrossChainContractAddr = "0x1234"
+	cfg.DBConfig.MaxOpenConns = 0
+	cfg.AdminConfig.KeyRotationEnabled = true
+
+	issues := cfg.Check()
+	fields := make(map[string]Severity)
+	for _, i := range issues {
+		fields[i.Field] = i.Severity
+	}
+	require.Equal(t, SeverityError, fields["mechain-relayer.monitor_channel_list"])
+	require.Equal(t, SeverityError, fields["mechain-relayer.bls_private_key"])
+	require.Equal(t, SeverityWarning, fields["bsc_config.chain_id"])
+	require.Equal(t, SeverityError, fields["relay_config.cross_chain_contract_addr"])
+	require.Equal(t, SeverityError, fields["db_config.max_open_conns"])
+	require.Equal(t, SeverityError, fields["admin_config.key_rotation_token_file"])
+	require.Len(t, issues.Errors(), 5)
+
+	err = cfg.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mechain-relayer.monitor_channel_list")
+	require.Contains(t, err.Error(), "db_config.max_open_conns")
+}
+
+func TestCheckLocalKeystore(t *testing.T) {
+	cfg, err := DecodeConfigFromFile("config.json")
+	require.NoError(t, err)
+	cfg.GreenfieldConfig.KeyType = KeyTypeLocalKeystore
+	cfg.GreenfieldConfig.PrivateKey = ""
+	cfg.GreenfieldConfig.BlsPrivateKey = ""
+	cfg.GreenfieldConfig.KeystoreFile = "config.json"
+	t.Setenv(KeystorePasswordEnv, "password")
+
+	fields := make(map[string]Severity)
+	for _, i := range cfg.Check().Errors() {
+		fields[i.Field] = i.Severity
+	}
+	require.NotContains(t, fields, "mechain-relayer.keystore_file")
+	require.Equal(t, SeverityError, fields["mechain-relayer.bls_keystore_file"])
+	require.NotContains(t, fields, "mechain-relayer.keystore_password_file")
+}
+
+func TestCheckWarnsOfValuesIgnoredByKeyType(t *testing.T) {
+	cfg, err := DecodeConfigFromFile("config.json")
+	require.NoError(t, err)
+	cfg.BSCConfig.KeyType = KeyTypeEnvSecret
+	cfg.BSCConfig.SecretName = "BSC_KEY"
+	cfg.DBConfig.Dialect = DBDialectMysql
+	cfg.DBConfig.Username = "root"
+	cfg.DBConfig.KeyType = KeyTypeEnvSecret
+	cfg.DBConfig.SecretName = "DB_PASS"
+
+	fields := make(map[string]Severity)
+	for _, i := range cfg.Check() {
+		fields[i.Field] = i.Severity
+	}
+	require.Equal(t, SeverityWarning, fields["bsc_config.private_key"])
+	require.Equal(t, SeverityWarning, fields["db_config.password"])
+	require.NotContains(t, fields, "mechain-relayer.private_key")
+}
+
+func TestKeyFiles(t *testing.T) {
+	cfg, err := DecodeConfigFromFile("config.json")
+	require.NoError(t, err)
+	cfg.GreenfieldConfig.KeyType = KeyTypeLocalKeystore
+	cfg.GreenfieldConfig.KeystoreFile = "key.json"
+	cfg.GreenfieldConfig.BlsKeystoreFile = "bls_key.json"
+	cfg.BSCConfig.KeyType = KeyTypeFileSecret
+	cfg.BSCConfig.SecretName = "bsc_key"
+	cfg.SecretConfig.SecretDir = "/secrets"
+	require.Equal(t, []string{"key.json", "bls_key.json", filepath.Join("/secrets", "bsc_key")}, cfg.KeyFiles())
+}
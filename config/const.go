@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 const (
 	FlagConfigPath          = "config-path"
 	FlagConfigType          = "config-type"
@@ -17,9 +19,45 @@ const (
 	AWSConfig              = "aws"
 	KeyTypeLocalPrivateKey = "local_private_key"
 	KeyTypeAWSPrivateKey   = "aws_private_key"
+	KeyTypeLocalKeystore   = "local_keystore"
+	KeyTypeRemoteSigner    = "remote_signer"
+	KeyTypeVaultSecret     = "vault_secret"
+	KeyTypeFileSecret      = "file_secret"
+	KeyTypeEnvSecret       = "env_secret"
+
+	SecretFieldPrivateKey    = "private_key"
+	SecretFieldBlsPrivateKey = "bls_private_key"
+	SecretFieldDBPass        = "db_pass"
 
 	ConfigType       = "CONFIG_TYPE"
 	ConfigFilePath   = "CONFIG_FILE_PATH"
 	ConfigDBPass     = "DB_PASSWORD"
 	ConfigDBUserName = "DB_USERNAME"
+
+	KeystorePasswordEnv = "KEYSTORE_PASSWORD"
+
+	DefaultBSCCatchUpBatchSize = 1000
+	DefaultBSCCatchUpThreshold = 100
+	DefaultBSCStuckTxTimeout   = 60 * time.Second
+	DefaultGasPriceBumpPercent = 20
+	MinGasPriceBumpPercent     = 10
+
+	FeeStrategyLegacy            = "legacy"
+	FeeStrategyEIP1559           = "eip1559"
+	DefaultFeeHistoryBlocks      = 20
+	DefaultPriorityFeePercentile = 50
+
+	DefaultGasLimitMarginPercent = 20
+	DefaultBSCMinGasLimit        = 100000
+	DefaultBSCMaxGasLimit        = 10000000
+
+	DefaultGreenfieldPrefetchSize = 10
+	MaxGreenfieldPrefetchSize     = 100
+	MaxClaimBatchBytes            = 1 << 20 // the default max tx bytes of CometBFT
+
+	DefaultAckTxTimeout = 30 * time.Second
+
+	DefaultSlashingProtectionDBPath = "slashing_protection.db"
+
+	DefaultKeyRotationAddr = "127.0.0.1:8081"
 )
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+
+	// EnvOverlayPrefix is the prefix of the environment variables overriding config fields, the full name is
+	// MECHAIN_RELAYER_<SECTION>_<FIELD>, e.g. MECHAIN_RELAYER_BSC_RPC_ADDRS.
+	EnvOverlayPrefix = "MECHAIN_RELAYER_"
+)
+
+// Overrides are values given on the command line, they take precedence over the config file and the environment.
+type Overrides struct {
+	PrivateKey    string
+	BlsPrivateKey string
+	DBUsername    string
+	DBPassword    string
+}
+
+type LoadOptions struct {
+	// ConfigType is LocalConfig to read FilePath, or AWSConfig to read the json config stored in AWS secrets manager
+	ConfigType   string
+	FilePath     string
+	AWSSecretKey string
+	AWSRegion    string
+	Overrides    Overrides
+}
+
+// LoadConfig reads the config and applies, from the lowest to the highest precedence, the legacy DB_USERNAME and
+// DB_PASSWORD environment variables, the MECHAIN_RELAYER_* environment overlay and the overrides. The config is not
+// validated.
+func LoadConfig(opts *LoadOptions) (*Config, error) {
+	var (
+		cfg *Config
+		err error
+	)
+	switch opts.ConfigType {
+	case AWSConfig:
+		if opts.AWSSecretKey == "" || opts.AWSRegion == "" {
+			return nil, fmt.Errorf("aws secret key and aws region are required for config type %s", AWSConfig)
+		}
+		var content string
+		content, err = (&AWSSecretProvider{Region: opts.AWSRegion}).GetSecret(opts.AWSSecretKey)
+		if err != nil {
+			return nil, fmt.Errorf("get aws config error, err=%s", err.Error())
+		}
+		cfg, err = DecodeConfig([]byte(content), FormatJSON)
+		if err != nil {
+			return nil, err
+		}
+	case LocalConfig:
+		if opts.FilePath == "" {
+			return nil, fmt.Errorf("config file path is required for config type %s", LocalConfig)
+		}
+		cfg, err = DecodeConfigFromFile(opts.FilePath)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("config type should be %s or %s", LocalConfig, AWSConfig)
+	}
+
+	if username, ok := os.LookupEnv(ConfigDBUserName); ok && username != "" {
+		cfg.DBConfig.Username = username
+	}
+	if password, ok := os.LookupEnv(ConfigDBPass); ok && password != "" {
+		cfg.DBConfig.Password = password
+	}
+	if err = ApplyEnvOverlay(cfg, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	cfg.applyOverrides(&opts.Overrides)
+	return cfg, nil
+}
+
+func (cfg *Config) applyOverrides(o *Overrides) {
+	if o.PrivateKey != "" {
+		cfg.GreenfieldConfig.PrivateKey = o.PrivateKey
+		cfg.BSCConfig.PrivateKey = o.PrivateKey
+	}
+	if o.BlsPrivateKey != "" {
+		cfg.GreenfieldConfig.BlsPrivateKey = o.BlsPrivateKey
+	}
+	if o.DBUsername != "" {
+		cfg.DBConfig.Username = o.DBUsername
+	}
+	if o.DBPassword != "" {
+		cfg.DBConfig.Password = o.DBPassword
+	}
+}
+
+// DecodeConfig decodes the config in the format without validating it. YAML and TOML configs use the same field
+// names as JSON configs.
+func DecodeConfig(content []byte, format string) (*Config, error) {
+	if format != FormatJSON {
+		var fields map[string]interface{}
+		var err error
+		switch format {
+		case FormatYAML:
+			err = yaml.Unmarshal(content, &fields)
+		case FormatTOML:
+			err = toml.Unmarshal(content, &fields)
+		default:
+			return nil, fmt.Errorf("unsupported config format %s", format)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s config, err=%s", format, err.Error())
+		}
+		if content, err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+	}
+	var config Config
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode config, err=%s", err.Error())
+	}
+	return &config, nil
+}
+
+func DecodeConfigFromJson(content []byte) (*Config, error) {
+	return DecodeConfig(content, FormatJSON)
+}
+
+// DecodeConfigFromFile reads and decodes the config file without validating it, the format is given by the file
+// extension.
+func DecodeConfigFromFile(filePath string) (*Config, error) {
+	var format string
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		format = FormatJSON
+	case ".yaml", ".yml":
+		format = FormatYAML
+	case ".toml":
+		format = FormatTOML
+	default:
+		return nil, fmt.Errorf("unsupported config file %s, the extension should be .json, .yaml, .yml or .toml", filePath)
+	}
+	bz, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeConfig(bz, format)
+}
+
+// ApplyEnvOverlay sets the config fields given by MECHAIN_RELAYER_<SECTION>_<FIELD> environment variables, where
+// SECTION is the env tag of the section and FIELD is the upper case json name of the field. Slices are comma
+// separated, an empty value sets an empty slice.
+func ApplyEnvOverlay(cfg *Config, lookup func(key string) (string, bool)) error {
+	return forEachEnvField(cfg, func(name string, field reflect.Value) error {
+		value, ok := lookup(name)
+		if !ok {
+			return nil
+		}
+		if err := setEnvField(field, value); err != nil {
+			return fmt.Errorf("invalid value of %s, err=%s", name, err.Error())
+		}
+		return nil
+	})
+}
+
+// EnvOverlayNames returns the names of all environment variables of the overlay.
+func EnvOverlayNames() []string {
+	names := make([]string, 0)
+	_ = forEachEnvField(&Config{}, func(name string, _ reflect.Value) error {
+		names = append(names, name)
+		return nil
+	})
+	return names
+}
+
+func forEachEnvField(cfg *Config, fn func(name string, field reflect.Value) error) error {
+	v := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		section := v.Type().Field(i).Tag.Get("env")
+		sectionValue := v.Field(i)
+		for j := 0; j < sectionValue.NumField(); j++ {
+			jsonName := strings.Split(sectionValue.Type().Field(j).Tag.Get("json"), ",")[0]
+			if jsonName == "" || jsonName == "-" {
+				continue
+			}
+			name := EnvOverlayPrefix + section + "_" + strings.ToUpper(strings.ReplaceAll(jsonName, "-", "_"))
+			if err := fn(name, sectionValue.Field(j)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setEnvField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Slice:
+		items := make([]string, 0)
+		if strings.TrimSpace(value) != "" {
+			items = strings.Split(value, ",")
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setEnvField(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDecodeYamlAndTomlConfig(t *testing.T) {
+	expected, err := DecodeConfigFromFile("config.json")
+	require.NoError(t, err)
+
+	bz, err := os.ReadFile("config.json")
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(bz, &fields))
+
+	dir := t.TempDir()
+	yamlBz, err := yaml.Marshal(fields)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), yamlBz, 0o600))
+	tomlBz, err := toml.Marshal(fields)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.toml"), tomlBz, 0o600))
+
+	for _, f := range []string{"config.yaml", "config.toml"} {
+		cfg, err := DecodeConfigFromFile(filepath.Join(dir, f))
+		require.NoError(t, err, f)
+		require.Equal(t, expected, cfg, f)
+	}
+
+	_, err = DecodeConfigFromFile(filepath.Join(dir, "config.ini"))
+	require.Error(t, err)
+}
+
+func TestApplyEnvOverlay(t *testing.T) {
+	cfg, err := DecodeConfigFromFile("config.json")
+	require.NoError(t, err)
+
+	env := map[string]string{
+		"MECHAIN_RELAYER_MECHAIN_PRIVATE_KEY":          "abcd",
+		"MECHAIN_RELAYER_MECHAIN_MONITOR_CHANNEL_LIST": "1, 2,10",
+		"MECHAIN_RELAYER_BSC_RPC_ADDRS":                "http://a,http://b",
+		"MECHAIN_RELAYER_BSC_OP_BNB":                   "true",
+		"MECHAIN_RELAYER_BSC_START_HEIGHT":             "100",
+		"MECHAIN_RELAYER_ADMIN_PORT":                   "9090",
+		"MECHAIN_RELAYER_DB_PASSWORD":                  "secret",
+		"MECHAIN_RELAYER_PORT":                         "tcp://10.0.0.1:8080", // set by kubernetes for a service, ignored
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	require.NoError(t, ApplyEnvOverlay(cfg, lookup))
+	require.Equal(t, "abcd", cfg.GreenfieldConfig.PrivateKey)
+	require.Equal(t, []uint8{1, 2, 10}, cfg.GreenfieldConfig.MonitorChannelList)
+	require.Equal(t, []string{"http://a", "http://b"}, cfg.BSCConfig.RPCAddrs)
+	require.True(t, cfg.BSCConfig.OpBNB)
+	require.Equal(t, uint64(100), cfg.BSCConfig.StartHeight)
+	require.Equal(t, uint16(9090), cfg.AdminConfig.Port)
+	require.Equal(t, "secret", cfg.DBConfig.Password)
+
+	env = map[string]string{"MECHAIN_RELAYER_ADMIN_PORT": "70000"}
+	require.Error(t, ApplyEnvOverlay(cfg, lookup))
+
+	require.Contains(t, EnvOverlayNames(), "MECHAIN_RELAYER_VOTE_POOL_QUERY_INTERVAL_IN_MILLISECOND")
+}
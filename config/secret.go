@@ -0,0 +1,200 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultVaultMount = "secret"
+	VaultTokenEnv     = "VAULT_TOKEN"
+	vaultTimeout      = 10 * time.Second
+)
+
+// SecretProvider reads secrets by name. A secret is either a JSON object holding named fields, as stored in AWS
+// secrets manager and Vault, or a plain value.
+type SecretProvider interface {
+	GetSecret(name string) (string, error)
+}
+
+// SecretConfig configures the secret providers selected by the key_type of the other sections.
+type SecretConfig struct {
+	VaultAddr  string `json:"vault_addr"`
+	VaultToken string `json:"vault_token"` // the VAULT_TOKEN environment variable is read if empty
+	VaultMount string `json:"vault_mount"` // mount path of the KV v2 secrets engine, "secret" by default
+	SecretDir  string `json:"secret_dir"`  // directory of relative secret file names for key_type file_secret
+}
+
+func (cfg *SecretConfig) Check() Issues {
+	is := make(Issues, 0)
+	if cfg.VaultAddr != "" && !strings.Contains(cfg.VaultAddr, "://") {
+		is.addError("vault_addr", "%q should be an url with scheme", cfg.VaultAddr)
+	}
+	return is
+}
+
+// IsSecretKeyType reports whether the keys of keyType are read from a secret provider.
+func IsSecretKeyType(keyType string) bool {
+	switch keyType {
+	case KeyTypeAWSPrivateKey, KeyTypeVaultSecret, KeyTypeFileSecret, KeyTypeEnvSecret:
+		return true
+	}
+	return false
+}
+
+// NewSecretProvider returns the secret provider of keyType. awsRegion is only used by aws_private_key.
+func (cfg *Config) NewSecretProvider(keyType, awsRegion string) (SecretProvider, error) {
+	switch keyType {
+	case KeyTypeAWSPrivateKey:
+		return &AWSSecretProvider{Region: awsRegion}, nil
+	case KeyTypeVaultSecret:
+		token := cfg.SecretConfig.VaultToken
+		if token == "" {
+			token = os.Getenv(VaultTokenEnv)
+		}
+		mount := cfg.SecretConfig.VaultMount
+		if mount == "" {
+			mount = DefaultVaultMount
+		}
+		return &VaultSecretProvider{Addr: cfg.SecretConfig.VaultAddr, Token: token, Mount: mount}, nil
+	case KeyTypeFileSecret:
+		return &FileSecretProvider{Dir: cfg.SecretConfig.SecretDir}, nil
+	case KeyTypeEnvSecret:
+		return &EnvSecretProvider{}, nil
+	default:
+		return nil, fmt.Errorf("key_type %s does not read secrets", keyType)
+	}
+}
+
+// LookupSecret reads the secret name from the provider of keyType. If the secret is a JSON object, the value of field
+// is returned, otherwise the whole secret.
+func (cfg *Config) LookupSecret(keyType, awsRegion, name, field string) (string, error) {
+	provider, err := cfg.NewSecretProvider(keyType, awsRegion)
+	if err != nil {
+		return "", err
+	}
+	secret, err := provider.GetSecret(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s, err=%s", name, err.Error())
+	}
+	return SecretField(secret, field)
+}
+
+// ResolveSecret returns the secret field of name from the provider of a secret keyType, or value for the other key
+// types. The key type selects the only source, a value set in the config is not used for a secret key type.
+func (cfg *Config) ResolveSecret(keyType, awsRegion, name, field, value string) (string, error) {
+	if IsSecretKeyType(keyType) {
+		return cfg.LookupSecret(keyType, awsRegion, name, field)
+	}
+	return value, nil
+}
+
+// SecretField returns the string field of a JSON object secret, or the trimmed secret if it is not a JSON object.
+func SecretField(secret, field string) (string, error) {
+	trimmed := strings.TrimSpace(secret)
+	if !strings.HasPrefix(trimmed, "{") {
+		return trimmed, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(trimmed), &fields); err != nil {
+		return "", fmt.Errorf("failed to decode secret, err=%s", err.Error())
+	}
+	value, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret has no string field %s", field)
+	}
+	return value, nil
+}
+
+// AWSSecretProvider reads secrets from AWS secrets manager.
+type AWSSecretProvider struct {
+	Region string
+}
+
+func (p *AWSSecretProvider) GetSecret(name string) (string, error) {
+	return GetSecret(name, p.Region)
+}
+
+// VaultSecretProvider reads secrets from the HashiCorp Vault KV v2 secrets engine, the data of a secret is returned as
+// a JSON object.
+type VaultSecretProvider struct {
+	Addr  string
+	Token string
+	Mount string
+}
+
+func (p *VaultSecretProvider) GetSecret(name string) (string, error) {
+	if p.Addr == "" {
+		return "", fmt.Errorf("vault_addr is not configured")
+	}
+	endpoint := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(p.Addr, "/"), strings.Trim(p.Mount, "/"),
+		(&url.URL{Path: strings.TrimLeft(name, "/")}).EscapedPath())
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	resp, err := (&http.Client{Timeout: vaultTimeout}).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d, body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var result struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err = json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode vault response, err=%s", err.Error())
+	}
+	if len(result.Data.Data) == 0 || string(result.Data.Data) == "null" {
+		return "", fmt.Errorf("vault secret %s has no data", name)
+	}
+	return string(result.Data.Data), nil
+}
+
+// FileSecretProvider reads secrets from files, relative names are resolved against Dir.
+type FileSecretProvider struct {
+	Dir string
+}
+
+func (p *FileSecretProvider) GetSecret(name string) (string, error) {
+	bz, err := os.ReadFile(p.Path(name))
+	if err != nil {
+		return "", err
+	}
+	return string(bz), nil
+}
+
+// Path returns the path of the file of secret name.
+func (p *FileSecretProvider) Path(name string) string {
+	if !filepath.IsAbs(name) && p.Dir != "" {
+		return filepath.Join(p.Dir, name)
+	}
+	return name
+}
+
+// EnvSecretProvider reads secrets from environment variables.
+type EnvSecretProvider struct{}
+
+func (p *EnvSecretProvider) GetSecret(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
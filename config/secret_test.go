@@ -0,0 +1,81 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestVaultServer stands in for a Vault dev server with one KV v2 secret.
+func newTestVaultServer(t *testing.T, token string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/relayer/bsc" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"private_key":"abcd"},"metadata":{"version":1}}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLookupSecret(t *testing.T) {
+	server := newTestVaultServer(t, "root")
+	secretDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(secretDir, "bls"), []byte("1234\n"), 0o600))
+	t.Setenv("TEST_DB_PASS", `{"db_pass":"pass"}`)
+	t.Setenv(VaultTokenEnv, "root")
+
+	cfg := &Config{SecretConfig: SecretConfig{VaultAddr: server.URL, SecretDir: secretDir}}
+
+	value, err := cfg.LookupSecret(KeyTypeVaultSecret, "", "relayer/bsc", SecretFieldPrivateKey)
+	require.NoError(t, err)
+	require.Equal(t, "abcd", value)
+	_, err = cfg.LookupSecret(KeyTypeVaultSecret, "", "relayer/missing", SecretFieldPrivateKey)
+	require.Error(t, err)
+
+	value, err = cfg.LookupSecret(KeyTypeFileSecret, "", "bls", SecretFieldBlsPrivateKey)
+	require.NoError(t, err)
+	require.Equal(t, "1234", value)
+
+	value, err = cfg.LookupSecret(KeyTypeEnvSecret, "", "TEST_DB_PASS", SecretFieldDBPass)
+	require.NoError(t, err)
+	require.Equal(t, "pass", value)
+	_, err = cfg.LookupSecret(KeyTypeEnvSecret, "", "TEST_DB_PASS", SecretFieldPrivateKey)
+	require.Error(t, err)
+
+	_, err = cfg.LookupSecret(KeyTypeLocalPrivateKey, "", "name", SecretFieldPrivateKey)
+	require.Error(t, err)
+}
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("TEST_DB_PASS", `{"db_pass":"secret"}`)
+	cfg := &Config{}
+
+	// a secret key type reads the provider even if a value is configured
+	value, err := cfg.ResolveSecret(KeyTypeEnvSecret, "", "TEST_DB_PASS", SecretFieldDBPass, "configured")
+	require.NoError(t, err)
+	require.Equal(t, "secret", value)
+	_, err = cfg.ResolveSecret(KeyTypeEnvSecret, "", "TEST_MISSING", SecretFieldDBPass, "configured")
+	require.Error(t, err)
+
+	value, err = cfg.ResolveSecret(KeyTypeLocalPrivateKey, "", "TEST_DB_PASS", SecretFieldDBPass, "configured")
+	require.NoError(t, err)
+	require.Equal(t, "configured", value)
+}
+
+func TestVaultSecretProviderRejectsWrongToken(t *testing.T) {
+	server := newTestVaultServer(t, "root")
+	_, err := (&VaultSecretProvider{Addr: server.URL, Token: "wrong", Mount: DefaultVaultMount}).GetSecret("relayer/bsc")
+	require.Error(t, err)
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/zkMeLabs/mechain-relayer/common"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in the config. Errors make the config unusable, warnings point out risky settings.
+type Issue struct {
+	Severity Severity
+	Field    string
+	Message  string
+}
+
+func (i *Issue) Error() string {
+	return fmt.Sprintf("%s: %s", i.Field, i.Message)
+}
+
+type Issues []*Issue
+
+func (is *Issues) addError(field, format string, args ...interface{}) {
+	*is = append(*is, &Issue{Severity: SeverityError, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (is *Issues) addWarning(field, format string, args ...interface{}) {
+	*is = append(*is, &Issue{Severity: SeverityWarning, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// withSection prefixes the fields of the issues with the json name of the config section.
+func (is Issues) withSection(section string) Issues {
+	for _, i := range is {
+		i.Field = section + "." + i.Field
+	}
+	return is
+}
+
+func (is Issues) filter(severity Severity) Issues {
+	filtered := make(Issues, 0)
+	for _, i := range is {
+		if i.Severity == severity {
+			filtered = append(filtered, i)
+		}
+	}
+	return filtered
+}
+
+func (is Issues) Errors() Issues {
+	return is.filter(SeverityError)
+}
+
+func (is Issues) Warnings() Issues {
+	return is.filter(SeverityWarning)
+}
+
+// Err returns an error listing all issues of error severity, or nil if there is none.
+func (is Issues) Err() error {
+	errs := is.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	return fmt.Errorf("invalid config: %s", strings.Join(msgs, "; "))
+}
+
+// checkKeyType checks that keyType is one of the supported key types, local_private_key and aws_private_key are
+// supported by default.
+func checkKeyType(is *Issues, keyType string, supported ...string) {
+	supported = append([]string{KeyTypeLocalPrivateKey, KeyTypeAWSPrivateKey}, supported...)
+	if keyType == "" {
+		is.addError("key_type", "should not be empty")
+		return
+	}
+	for _, t := range supported {
+		if keyType == t {
+			return
+		}
+	}
+	is.addError("key_type", "only supports %s", strings.Join(supported, ", "))
+}
+
+// checkIgnoredValue warns that a key or password set in the config is ignored, since keyType reads it from a keystore
+// file, a remote signer or a secret provider.
+func checkIgnoredValue(is *Issues, field, value, keyType string) {
+	if value != "" && (keyType == KeyTypeLocalKeystore || keyType == KeyTypeRemoteSigner || IsSecretKeyType(keyType)) {
+		is.addWarning(field, "is ignored for key_type %s", keyType)
+	}
+}
+
+// checkKeystoreFile checks that the keystore file of key_type local_keystore exists.
+func checkKeystoreFile(is *Issues, field, file string) {
+	if file == "" {
+		is.addError(field, "should not be empty for key_type %s", KeyTypeLocalKeystore)
+	} else if _, err := os.Stat(file); err != nil {
+		is.addError(field, "%s is not readable, err=%s", file, err.Error())
+	}
+}
+
+// checkKeystorePassword checks that a keystore password is provided by a file or the KEYSTORE_PASSWORD environment
+// variable.
+func checkKeystorePassword(is *Issues, passwordFile string) {
+	if passwordFile == "" {
+		if _, ok := os.LookupEnv(KeystorePasswordEnv); !ok {
+			is.addError("keystore_password_file", "should not be empty if %s is not set", KeystorePasswordEnv)
+		}
+	} else if _, err := os.Stat(passwordFile); err != nil {
+		is.addError("keystore_password_file", "%s is not readable, err=%s", passwordFile, err.Error())
+	}
+}
+
+// checkHexKey checks that a private key is the hex encoding of 32 bytes.
+func checkHexKey(is *Issues, field, key string) {
+	if key == "" {
+		is.addError(field, "should not be empty")
+		return
+	}
+	bz, err := hex.DecodeString(key)
+	if err != nil {
+		is.addError(field, "should be hex encoded without 0x prefix")
+		return
+	}
+	if len(bz) != 32 {
+		is.addError(field, "should be 32 bytes, got %d bytes", len(bz))
+	}
+}
+
+func checkRPCAddrs(is *Issues, addrs []string) {
+	if len(addrs) == 0 {
+		is.addError("rpc_addrs", "should not be empty")
+	}
+	for i, addr := range addrs {
+		if !strings.Contains(addr, "://") {
+			is.addError(fmt.Sprintf("rpc_addrs[%d]", i), "%q should be an url with scheme", addr)
+		}
+	}
+}
+
+// LogLevels are the levels supported by the logger.
+var LogLevels = map[string]bool{
+	"CRITICAL": true,
+	"ERROR":    true,
+	"WARNING":  true,
+	"NOTICE":   true,
+	"INFO":     true,
+	"DEBUG":    true,
+}
+
+// KnownChainIds are the evm-compatible chains the relayer is deployed for.
+var KnownChainIds = map[uint64]string{
+	common.BSCChainId:        "BSC",
+	common.BSCTestnetChainId: "BSC testnet",
+	common.OpBNBChainId:      "opBNB",
+	common.PolygonChainId:    "Polygon",
+	common.ScrollChainId:     "Scroll",
+	common.LineaChainId:      "Linea",
+	common.MantleChainId:     "Mantle",
+	common.ArbitrumChainId:   "Arbitrum",
+	common.OptimismChainId:   "Optimism",
+}
+
+func checkHexAddr(is *Issues, field, addr string) {
+	if addr == "" {
+		is.addError(field, "should not be empty")
+	} else if !ethcommon.IsHexAddress(addr) {
+		is.addError(field, "%q is not a hex address", addr)
+	} else if ethcommon.HexToAddress(addr) == (ethcommon.Address{}) {
+		is.addError(field, "should not be the zero address")
+	}
+}
+
+// checkFeeStrategy checks that the fee strategy is empty, so the default one is used, or a supported one.
+func checkFeeStrategy(is *Issues, strategy string) {
+	if strategy != "" && strategy != FeeStrategyLegacy && strategy != FeeStrategyEIP1559 {
+		is.addError("fee_strategy", "only supports %s, %s", FeeStrategyLegacy, FeeStrategyEIP1559)
+	}
+}
+
+// checkFees checks the fixed gas price and the fee caps of a chain, gasPriceField is the name of its fixed gas price.
+// A max_gas_price of 0 is no cap.
+func checkFees(is *Issues, gasPriceField string, gasPrice, maxGasPrice, priorityFeePercentile, maxPriorityFeePerGas uint64) {
+	if maxGasPrice != 0 && gasPrice > maxGasPrice {
+		is.addError("max_gas_price", "should not be less than %s", gasPriceField)
+	}
+	if priorityFeePercentile > 100 {
+		is.addError("priority_fee_percentile", "should be within [0, 100]")
+	}
+	if maxGasPrice != 0 && maxPriorityFeePerGas > maxGasPrice {
+		is.addWarning("max_priority_fee_per_gas", "is larger than max_gas_price, the priority fee is capped by max_gas_price")
+	}
+}
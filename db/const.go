@@ -7,4 +7,33 @@ const (
 	SelfVoted TxStatus = 1 // Tx is only voted by local relayer
 	AllVoted  TxStatus = 2 // TX is already voted by enough validators, more than (2/3) * (# of validators) valid votes collected.
 	Delivered TxStatus = 3 // Tx is delivered to the dest chain
+	Submitted TxStatus = 4 // the claim tx of the local relayer is broadcast, its receipt is not known yet
+	Confirmed TxStatus = 5 // the claim tx of the local relayer is included and succeeded
+	Failed    TxStatus = 6 // the claim tx of the local relayer reverted or was dropped, the tx is relayed again
 )
+
+// IsAllVoted reports whether the tx has collected enough votes, which holds for every status after AllVoted.
+func (s TxStatus) IsAllVoted() bool {
+	return s >= AllVoted
+}
+
+func (s TxStatus) String() string {
+	switch s {
+	case Saved:
+		return "saved"
+	case SelfVoted:
+		return "self_voted"
+	case AllVoted:
+		return "all_voted"
+	case Delivered:
+		return "delivered"
+	case Submitted:
+		return "submitted"
+	case Confirmed:
+		return "confirmed"
+	case Failed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
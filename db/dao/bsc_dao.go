@@ -8,6 +8,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/zkMeLabs/mechain-relayer/common"
 	"github.com/zkMeLabs/mechain-relayer/db"
 	"github.com/zkMeLabs/mechain-relayer/db/model"
 )
@@ -31,6 +32,26 @@ func (d *BSCDao) GetLatestBlock() (*model.BscBlock, error) {
 	return &block, nil
 }
 
+func (d *BSCDao) GetBlockAtHeight(height uint64) (*model.BscBlock, error) {
+	block := model.BscBlock{}
+	err := d.DB.Model(model.BscBlock{}).Where("height = ?", height).Take(&block).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// GetLatestBlockBelowHeight returns the highest stored block below the height, an empty block is returned if there is
+// none. The stored heights have gaps, the catch-up mode only saves the blocks with packages and the last scanned one.
+func (d *BSCDao) GetLatestBlockBelowHeight(height uint64) (*model.BscBlock, error) {
+	block := model.BscBlock{}
+	err := d.DB.Model(model.BscBlock{}).Where("height < ?", height).Order("height desc").Take(&block).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &block, nil
+}
+
 func (d *BSCDao) GetPackagesByStatus(status db.TxStatus) ([]*model.BscRelayPackage, error) {
 	votedTxs := make([]*model.BscRelayPackage, 0)
 	err := d.DB.Where("status = ? ", status).Find(&votedTxs).Order("tx_time asc").Error
@@ -59,9 +80,9 @@ func (d *BSCDao) GetLeastSavedPackagesHeight() (uint64, error) {
 	return uint64(result.Int64), nil
 }
 
-func (d *BSCDao) GetLatestOracleSequenceByStatus(status db.TxStatus) (int64, error) {
+func (d *BSCDao) GetLatestOracleSequenceByStatus(statuses ...db.TxStatus) (int64, error) {
 	var result sql.NullInt64
-	res := d.DB.Table("bsc_relay_package").Select("MAX(oracle_sequence)").Where("status = ?", status)
+	res := d.DB.Table("bsc_relay_package").Select("MAX(oracle_sequence)").Where("status IN (?)", statuses)
 	err := res.Row().Scan(&result)
 	if err != nil {
 		return 0, err
@@ -132,6 +153,23 @@ func (d *BSCDao) SaveBlockAndBatchPackages(b *model.BscBlock, pkgs []*model.BscR
 	})
 }
 
+// SaveBlocksAndBatchPackages saves blocks and packages scanned over a block range in one transaction.
+func (d *BSCDao) SaveBlocksAndBatchPackages(blocks []*model.BscBlock, pkgs []*model.BscRelayPackage) error {
+	return d.DB.Transaction(func(dbTx *gorm.DB) error {
+		if len(blocks) != 0 {
+			if err := dbTx.Create(blocks).Error; err != nil {
+				return err
+			}
+		}
+		if len(pkgs) != 0 {
+			if err := dbTx.Create(pkgs).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (d *BSCDao) SaveBatchPackages(pkgs []*model.BscRelayPackage) error {
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
 		if len(pkgs) != 0 {
@@ -162,24 +200,16 @@ func (d *BSCDao) DeleteBlockAndPackagesAndVotesAtHeight(height uint64) error {
 	})
 }
 
-func (d *BSCDao) DeleteBlocksBelowHeight(threshHold int64) error {
-	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		err := dbTx.Where("height < ?", threshHold).Delete(model.BscBlock{}).Error
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+// DeleteBlocksBelowHeight deletes the blocks below threshHold and returns how many were deleted.
+func (d *BSCDao) DeleteBlocksBelowHeight(threshHold int64) (int64, error) {
+	res := d.DB.Where("height < ?", threshHold).Delete(model.BscBlock{})
+	return res.RowsAffected, res.Error
 }
 
-func (d *BSCDao) DeletePackagesBelowHeightWithLimit(threshHold int64, limit int) error {
-	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		err := dbTx.Where("height < ?", threshHold).Delete(model.BscRelayPackage{}).Limit(limit).Error
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+// DeletePackagesBelowHeightWithLimit deletes at most limit packages below threshHold and returns how many were deleted.
+func (d *BSCDao) DeletePackagesBelowHeightWithLimit(threshHold int64, limit int) (int64, error) {
+	res := d.DB.Where("height < ?", threshHold).Limit(limit).Delete(model.BscRelayPackage{})
+	return res.RowsAffected, res.Error
 }
 
 func (d *BSCDao) ExistsUnprocessedPackage(threshHold int64) (bool, error) {
@@ -193,3 +223,60 @@ func (d *BSCDao) ExistsUnprocessedPackage(threshHold int64) (bool, error) {
 	}
 	return true, nil
 }
+
+// CountPackagesByStatus returns the number of packages of each status.
+func (d *BSCDao) CountPackagesByStatus() (map[db.TxStatus]int64, error) {
+	return countByStatus(d.DB, model.BscRelayPackage{})
+}
+
+// ResetPackagesByOracleSequence marks the packages of the oracle sequence as saved and deletes their votes, so they are
+// voted and relayed again. It returns the number of reset packages.
+func (d *BSCDao) ResetPackagesByOracleSequence(sequence uint64) (int64, error) {
+	var affected int64
+	err := d.DB.Transaction(func(dbTx *gorm.DB) error {
+		res := dbTx.Model(model.BscRelayPackage{}).Where("oracle_sequence = ?", sequence).Updates(map[string]interface{}{
+			"status":        db.Saved,
+			"claim_tx_hash": "",
+			"updated_time":  time.Now().Unix(),
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		affected = res.RowsAffected
+		return dbTx.Where("channel_id = ? and sequence = ?", common.OracleChannelId, sequence).Delete(model.Vote{}).Error
+	})
+	return affected, err
+}
+
+// ResetSelfVotedPackages marks the packages voted by the relayer but not by enough others as saved, so they are voted
+// again with a rotated BLS key. It returns the number of reset packages.
+func (d *BSCDao) ResetSelfVotedPackages() (int64, error) {
+	res := d.DB.Model(model.BscRelayPackage{}).Where("status = ?", db.SelfVoted).Updates(map[string]interface{}{
+		"status":       db.Saved,
+		"updated_time": time.Now().Unix(),
+	})
+	return res.RowsAffected, res.Error
+}
+
+// DeleteBlocksAndPackagesAboveHeight deletes the blocks and packages above the height together with the votes of the
+// deleted packages, the listener resumes from the next height.
+func (d *BSCDao) DeleteBlocksAndPackagesAboveHeight(height uint64) error {
+	return d.DB.Transaction(func(dbTx *gorm.DB) error {
+		sequences := make([]uint64, 0)
+		err := dbTx.Model(model.BscRelayPackage{}).Where("height > ?", height).Distinct().Pluck("oracle_sequence", &sequences).Error
+		if err != nil {
+			return err
+		}
+		if len(sequences) != 0 {
+			err = dbTx.Where("channel_id = ? and sequence in ?", common.OracleChannelId, sequences).Delete(model.Vote{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		err = dbTx.Where("height > ?", height).Delete(model.BscRelayPackage{}).Error
+		if err != nil {
+			return err
+		}
+		return dbTx.Where("height > ?", height).Delete(model.BscBlock{}).Error
+	})
+}
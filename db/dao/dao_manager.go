@@ -1,5 +1,11 @@
 package dao
 
+import (
+	"gorm.io/gorm"
+
+	"github.com/zkMeLabs/mechain-relayer/db"
+)
+
 type DaoManager struct {
 	GreenfieldDao *GreenfieldDao
 	VoteDao       *VoteDao
@@ -13,3 +19,20 @@ func NewDaoManager(greenfieldDao *GreenfieldDao, bscDao *BSCDao, voteDao *VoteDa
 		BSCDao:        bscDao,
 	}
 }
+
+func countByStatus(dbTx *gorm.DB, table interface{}) (map[db.TxStatus]int64, error) {
+	type statusCount struct {
+		Status db.TxStatus
+		Count  int64
+	}
+	rows := make([]*statusCount, 0)
+	err := dbTx.Model(table).Select("status, count(*) as count").Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[db.TxStatus]int64, len(rows))
+	for _, r := range rows {
+		counts[r.Status] = r.Count
+	}
+	return counts, nil
+}
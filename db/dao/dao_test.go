@@ -0,0 +1,172 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/zkMeLabs/mechain-relayer/db"
+	"github.com/zkMeLabs/mechain-relayer/db/dbtest"
+	"github.com/zkMeLabs/mechain-relayer/db/migration"
+	"github.com/zkMeLabs/mechain-relayer/db/model"
+)
+
+func newTestSqliteDB(t *testing.T) *gorm.DB {
+	relayerDB := dbtest.NewSqliteDB(t)
+	_, err := migration.NewMigrator(relayerDB).Up()
+	require.NoError(t, err)
+	return relayerDB
+}
+
+func TestSqliteBSCDao(t *testing.T) {
+	bscDao := NewBSCDao(newTestSqliteDB(t))
+
+	block, err := bscDao.GetLatestBlock()
+	require.NoError(t, err)
+	require.Equal(t, model.BscBlock{}, *block)
+
+	height, err := bscDao.GetLeastSavedPackagesHeight()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), height)
+
+	pkgs := []*model.BscRelayPackage{
+		{ChannelId: 0, OracleSequence: 1, PackageSequence: 1, TxHash: "0x1", Height: 10, Status: db.Saved},
+		{ChannelId: 0, OracleSequence: 1, PackageSequence: 2, TxHash: "0x1", TxIndex: 1, Height: 10, Status: db.Saved},
+	}
+	require.NoError(t, bscDao.SaveBlockAndBatchPackages(&model.BscBlock{BlockHash: "0xa", ParentHash: "0x9", Height: 10}, pkgs))
+
+	block, err = bscDao.GetLatestBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), block.Height)
+
+	height, err = bscDao.GetLeastSavedPackagesHeight()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), height)
+
+	require.NoError(t, bscDao.UpdateBatchPackagesStatus([]int64{pkgs[0].Id, pkgs[1].Id}, db.AllVoted))
+	seq, err := bscDao.GetLatestOracleSequenceByStatus(db.AllVoted)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), seq)
+
+	// failed packages are relayed again together with the all voted ones
+	require.NoError(t, bscDao.UpdateBatchPackagesStatus([]int64{pkgs[0].Id, pkgs[1].Id}, db.Failed))
+	seq, err = bscDao.GetLatestOracleSequenceByStatus(db.AllVoted)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), seq)
+	seq, err = bscDao.GetLatestOracleSequenceByStatus(db.AllVoted, db.Failed)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), seq)
+
+	deleted, err := bscDao.DeletePackagesBelowHeightWithLimit(11, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), deleted)
+	deleted, err = bscDao.DeletePackagesBelowHeightWithLimit(11, 10)
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+	deleted, err = bscDao.DeleteBlocksBelowHeight(11)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+}
+
+func TestSqliteVoteDao(t *testing.T) {
+	voteDao := NewVoteDao(newTestSqliteDB(t))
+
+	exist, err := voteDao.IsVoteExist(1, 2, "pubkey")
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	require.NoError(t, voteDao.SaveVote(&model.Vote{
+		Signature:    "sig",
+		ClaimPayload: []byte{1},
+		EventHash:    []byte{2},
+		ChannelId:    1,
+		Sequence:     2,
+		PubKey:       "pubkey",
+	}))
+	exist, err = voteDao.IsVoteExist(1, 2, "pubkey")
+	require.NoError(t, err)
+	require.True(t, exist)
+
+	// the unique index on channel id, sequence and pub key is enforced
+	require.Error(t, voteDao.SaveVote(&model.Vote{
+		Signature:    "sig2",
+		ClaimPayload: []byte{1},
+		EventHash:    []byte{3},
+		ChannelId:    1,
+		Sequence:     2,
+		PubKey:       "pubkey",
+	}))
+
+	evidence := &model.VoteEvidence{
+		EventType:            1,
+		ChannelId:            1,
+		Sequence:             2,
+		PubKey:               "pubkey",
+		ConflictingEventHash: "03",
+		ConflictingSignature: "sig2",
+		EventHash:            "02",
+		LocalPubKey:          "localpubkey",
+		LocalSignature:       "sig",
+		CreatedTime:          100,
+	}
+	require.NoError(t, voteDao.SaveVoteEvidence(evidence))
+	exist, err = voteDao.IsVoteEvidenceExist(1, 1, 2, "pubkey", "03")
+	require.NoError(t, err)
+	require.True(t, exist)
+	evidences, err := voteDao.GetVoteEvidences(100)
+	require.NoError(t, err)
+	require.Len(t, evidences, 1)
+	require.Equal(t, "sig2", evidences[0].ConflictingSignature)
+	evidences, err = voteDao.GetVoteEvidences(101)
+	require.NoError(t, err)
+	require.Empty(t, evidences)
+}
+
+func TestSqliteResetAndRewind(t *testing.T) {
+	relayerDB := newTestSqliteDB(t)
+	bscDao := NewBSCDao(relayerDB)
+	voteDao := NewVoteDao(relayerDB)
+
+	for height := uint64(10); height <= 12; height++ {
+		require.NoError(t, bscDao.SaveBlockAndBatchPackages(&model.BscBlock{BlockHash: "0xa", ParentHash: "0x9", Height: height},
+			[]*model.BscRelayPackage{{OracleSequence: height, PackageSequence: height, TxHash: "0x1", Height: height, Status: db.Delivered}}))
+		require.NoError(t, voteDao.SaveVote(&model.Vote{
+			Signature: "sig", ClaimPayload: []byte{1}, EventHash: []byte{2}, Sequence: height, PubKey: "pubkey",
+		}))
+	}
+
+	affected, err := bscDao.ResetPackagesByOracleSequence(11)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affected)
+	counts, err := bscDao.CountPackagesByStatus()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), counts[db.Saved])
+	require.Equal(t, int64(2), counts[db.Delivered])
+	exist, err := voteDao.IsVoteExist(0, 11, "pubkey")
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	require.NoError(t, bscDao.UpdateBatchPackagesStatus([]int64{1}, db.SelfVoted))
+	affected, err = bscDao.ResetSelfVotedPackages()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affected)
+	counts, err = bscDao.CountPackagesByStatus()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), counts[db.Saved])
+
+	block, err := bscDao.GetLatestBlockBelowHeight(12)
+	require.NoError(t, err)
+	require.Equal(t, uint64(11), block.Height)
+	block, err = bscDao.GetLatestBlockBelowHeight(10)
+	require.NoError(t, err)
+	require.Equal(t, model.BscBlock{}, *block)
+
+	require.NoError(t, bscDao.DeleteBlocksAndPackagesAboveHeight(10))
+	block, err = bscDao.GetLatestBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), block.Height)
+	votes, err := voteDao.CountVotes()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), votes)
+}
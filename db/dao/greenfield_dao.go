@@ -72,9 +72,9 @@ func (d *GreenfieldDao) GetTransactionByChannelIdAndSequence(channelId types.Cha
 // 	return result.Int64 + 1, nil
 // }
 
-func (d *GreenfieldDao) GetLatestSequenceByChannelIdAndStatus(channelId types.ChannelId, status db.TxStatus) (int64, error) {
+func (d *GreenfieldDao) GetLatestSequenceByChannelIdAndStatus(channelId types.ChannelId, statuses ...db.TxStatus) (int64, error) {
 	var result sql.NullInt64
-	res := d.DB.Table("greenfield_relay_transaction").Select("MAX(sequence)").Where("channel_id = ? and status = ?", channelId, status)
+	res := d.DB.Table("greenfield_relay_transaction").Select("MAX(sequence)").Where("channel_id = ? and status IN (?)", channelId, statuses)
 	err := res.Row().Scan(&result)
 	if err != nil {
 		return 0, err
@@ -150,24 +150,17 @@ func (d *GreenfieldDao) GetLatestSyncedTransaction() (*model.SyncLightBlockTrans
 	return &tx, nil
 }
 
-func (d *GreenfieldDao) DeleteBlocksBelowHeight(threshHold int64) error {
-	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		err := dbTx.Where("height < ?", threshHold).Delete(model.GreenfieldBlock{}).Error
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+// DeleteBlocksBelowHeight deletes the blocks below threshHold and returns how many were deleted.
+func (d *GreenfieldDao) DeleteBlocksBelowHeight(threshHold int64) (int64, error) {
+	res := d.DB.Where("height < ?", threshHold).Delete(model.GreenfieldBlock{})
+	return res.RowsAffected, res.Error
 }
 
-func (d *GreenfieldDao) DeleteTransactionsBelowHeightWithLimit(threshHold int64, limit int) error {
-	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		err := dbTx.Where("height < ?", threshHold).Delete(model.GreenfieldRelayTransaction{}).Limit(limit).Error
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+// DeleteTransactionsBelowHeightWithLimit deletes at most limit transactions below threshHold and returns how many were
+// deleted.
+func (d *GreenfieldDao) DeleteTransactionsBelowHeightWithLimit(threshHold int64, limit int) (int64, error) {
+	res := d.DB.Where("height < ?", threshHold).Limit(limit).Delete(model.GreenfieldRelayTransaction{})
+	return res.RowsAffected, res.Error
 }
 
 func (d *GreenfieldDao) ExistsUnprocessedTransaction(threshHold int64) (bool, error) {
@@ -181,3 +174,60 @@ func (d *GreenfieldDao) ExistsUnprocessedTransaction(threshHold int64) (bool, er
 	}
 	return true, nil
 }
+
+// CountTransactionsByStatus returns the number of transactions of each status.
+func (d *GreenfieldDao) CountTransactionsByStatus() (map[db.TxStatus]int64, error) {
+	return countByStatus(d.DB, model.GreenfieldRelayTransaction{})
+}
+
+// ResetTransaction marks the transaction as saved and deletes its votes, so it is voted and relayed again. It returns
+// the number of reset transactions.
+func (d *GreenfieldDao) ResetTransaction(channelId types.ChannelId, sequence uint64) (int64, error) {
+	var affected int64
+	err := d.DB.Transaction(func(dbTx *gorm.DB) error {
+		res := dbTx.Model(model.GreenfieldRelayTransaction{}).Where("channel_id = ? and sequence = ?", channelId, sequence).Updates(map[string]interface{}{
+			"status":          db.Saved,
+			"claimed_tx_hash": "",
+			"updated_time":    time.Now().Unix(),
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		affected = res.RowsAffected
+		return dbTx.Where("channel_id = ? and sequence = ?", channelId, sequence).Delete(model.Vote{}).Error
+	})
+	return affected, err
+}
+
+// ResetSelfVotedTransactions marks the transactions voted by the relayer but not by enough others as saved, so they are
+// voted again with a rotated BLS key. It returns the number of reset transactions.
+func (d *GreenfieldDao) ResetSelfVotedTransactions() (int64, error) {
+	res := d.DB.Model(model.GreenfieldRelayTransaction{}).Where("status = ?", db.SelfVoted).Updates(map[string]interface{}{
+		"status":       db.Saved,
+		"updated_time": time.Now().Unix(),
+	})
+	return res.RowsAffected, res.Error
+}
+
+// DeleteBlocksAndTransactionsAboveHeight deletes the blocks and transactions above the height together with the votes
+// of the deleted transactions, the listener resumes from the next height.
+func (d *GreenfieldDao) DeleteBlocksAndTransactionsAboveHeight(height uint64) error {
+	return d.DB.Transaction(func(dbTx *gorm.DB) error {
+		txs := make([]*model.GreenfieldRelayTransaction, 0)
+		err := dbTx.Select("channel_id", "sequence").Where("height > ?", height).Find(&txs).Error
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			err = dbTx.Where("channel_id = ? and sequence = ?", tx.ChannelId, tx.Sequence).Delete(model.Vote{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		err = dbTx.Where("height > ?", height).Delete(model.GreenfieldRelayTransaction{}).Error
+		if err != nil {
+			return err
+		}
+		return dbTx.Where("height > ?", height).Delete(model.GreenfieldBlock{}).Error
+	})
+}
@@ -81,12 +81,42 @@ func (d *VoteDao) SaveBatchVotes(votes []*model.Vote) error {
 	})
 }
 
-func (d *VoteDao) DeleteVotesBelowHeightWithLimit(threshHold int64, eventType uint32, limit int) error {
+// DeleteVotesBelowHeightWithLimit deletes at most limit votes of eventType below threshHold and returns how many were
+// deleted.
+func (d *VoteDao) DeleteVotesBelowHeightWithLimit(threshHold int64, eventType uint32, limit int) (int64, error) {
+	res := d.DB.Where("event_type = ? and height < ?", eventType, threshHold).Limit(limit).Delete(model.Vote{})
+	return res.RowsAffected, res.Error
+}
+
+func (d *VoteDao) CountVotes() (int64, error) {
+	var count int64
+	err := d.DB.Model(model.Vote{}).Count(&count).Error
+	return count, err
+}
+
+func (d *VoteDao) IsVoteEvidenceExist(eventType uint32, channelId uint8, sequence uint64, pubKey, conflictingEventHash string) (bool, error) {
+	exists := false
+	if err := d.DB.Raw(
+		"SELECT EXISTS(SELECT id FROM vote_evidence WHERE event_type = ? and channel_id = ? and sequence = ? and pub_key = ? and conflicting_event_hash = ?)",
+		eventType, channelId, sequence, pubKey, conflictingEventHash).Scan(&exists).Error; err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (d *VoteDao) SaveVoteEvidence(evidence *model.VoteEvidence) error {
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		err := dbTx.Where("event_type = ? and height < ?", eventType, threshHold).Delete(model.Vote{}).Limit(limit).Error
-		if err != nil {
-			return err
-		}
-		return nil
+		return dbTx.Create(evidence).Error
 	})
 }
+
+// GetVoteEvidences returns the evidences created at or after sinceTime in the order they were recorded, vote evidences
+// are never pruned with the votes.
+func (d *VoteDao) GetVoteEvidences(sinceTime int64) ([]*model.VoteEvidence, error) {
+	evidences := make([]*model.VoteEvidence, 0)
+	err := d.DB.Where("created_time >= ?", sinceTime).Order("id asc").Find(&evidences).Error
+	if err != nil {
+		return nil, err
+	}
+	return evidences, nil
+}
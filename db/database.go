@@ -0,0 +1,114 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+)
+
+const (
+	SqliteMemoryDB       = ":memory:"
+	SqliteBusyTimeoutMs  = 5000
+	SqliteJournalModeWAL = "wal"
+)
+
+// OpenDB opens the relayer database with the dialect in cfg. The username and password are only used by MySQL.
+func OpenDB(cfg *config.DBConfig, username, password string) (*gorm.DB, error) {
+	newLogger := logger.New(
+		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
+		logger.Config{
+			SlowThreshold:             time.Second,   // Slow SQL threshold
+			LogLevel:                  logger.Silent, // Log level
+			IgnoreRecordNotFoundError: true,          // Ignore ErrRecordNotFound error for logger
+			Colorful:                  true,          // Disable color
+		},
+	)
+
+	var dialector gorm.Dialector
+	switch cfg.Dialect {
+	case config.DBDialectMysql:
+		dialector = mysql.Open(fmt.Sprintf("%s:%s@%s", username, password, cfg.Url))
+	case config.DBDialectSqlite3:
+		dsn, err := sqliteDSN(cfg.Url)
+		if err != nil {
+			return nil, err
+		}
+		dialector = sqlite.Open(dsn)
+	default:
+		return nil, fmt.Errorf("unexpected DB dialect %s", cfg.Dialect)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: newLogger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open db error, err=%s", err.Error())
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+
+	if cfg.Dialect == config.DBDialectSqlite3 {
+		if isSqliteMemoryDB(cfg.Url) {
+			// every connection to an in-memory database opens a new empty database, so all queries have to share one
+			sqlDB.SetMaxOpenConns(1)
+			return db, nil
+		}
+		var journalMode string
+		if err = db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error; err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(journalMode, SqliteJournalModeWAL) {
+			return nil, fmt.Errorf("failed to enable WAL mode for sqlite db %s, journal_mode=%s", cfg.Url, journalMode)
+		}
+	}
+	return db, nil
+}
+
+// sqliteDSN converts the configured sqlite url, which is a plain file path or a "file:" uri, into a DSN that enables
+// WAL mode, waits for locks instead of failing fast and takes the write lock at the beginning of every transaction.
+// The parent directory of the database file is created if it does not exist.
+func sqliteDSN(url string) (string, error) {
+	if url == "" {
+		return "", fmt.Errorf("sqlite db file path should not be empty")
+	}
+	if isSqliteMemoryDB(url) {
+		return url, nil
+	}
+	path := strings.TrimPrefix(url, "file:")
+	if idx := strings.Index(path, "?"); idx >= 0 {
+		path = path[:idx]
+	}
+	if dir := filepath.Dir(path); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return "", fmt.Errorf("failed to create directory for sqlite db %s, err=%s", url, err.Error())
+		}
+	}
+	params := []string{
+		"_journal_mode=WAL",
+		fmt.Sprintf("_busy_timeout=%d", SqliteBusyTimeoutMs),
+		"_txlock=immediate",
+	}
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+	return url + separator + strings.Join(params, "&"), nil
+}
+
+func isSqliteMemoryDB(url string) bool {
+	return url == SqliteMemoryDB || strings.Contains(url, "mode=memory")
+}
@@ -0,0 +1,25 @@
+// Package dbtest provides the DB fixtures of the tests of the relayer DB.
+package dbtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/db"
+)
+
+// NewSqliteDB opens an empty sqlite3 DB in a temporary directory of the test.
+func NewSqliteDB(t testing.TB) *gorm.DB {
+	relayerDB, err := db.OpenDB(&config.DBConfig{
+		Dialect:      config.DBDialectSqlite3,
+		Url:          filepath.Join(t.TempDir(), "data", "relayer.db"),
+		MaxIdleConns: 10,
+		MaxOpenConns: 10,
+	}, "", "")
+	require.NoError(t, err)
+	return relayerDB
+}
@@ -0,0 +1,176 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a numbered schema change. Up applies the change and Down reverts it, a migration without Down is
+// irreversible. Both run in a DB transaction with the record of the migration, but MySQL commits every DDL statement
+// implicitly, so a failure after the DDL can leave the change applied but unrecorded. Up and Down therefore check the
+// schema before changing it, so that they can run again.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration records a migration that has been applied to the DB.
+type SchemaMigration struct {
+	Version     uint   `gorm:"primaryKey;autoIncrement:false"`
+	Name        string `gorm:"NOT NULL"`
+	AppliedTime int64  `gorm:"NOT NULL"`
+}
+
+func (*SchemaMigration) TableName() string {
+	return "schema_migration"
+}
+
+// Status describes whether a migration has been applied.
+type Status struct {
+	Version     uint
+	Name        string
+	Applied     bool
+	AppliedTime int64
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []*Migration
+}
+
+// NewMigrator returns a Migrator for all migrations of the relayer DB.
+func NewMigrator(db *gorm.DB) *Migrator {
+	return NewMigratorWithMigrations(db, Migrations)
+}
+
+func NewMigratorWithMigrations(db *gorm.DB, migrations []*Migration) *Migrator {
+	sorted := make([]*Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	return &Migrator{
+		db:         db,
+		migrations: sorted,
+	}
+}
+
+func (m *Migrator) init() error {
+	if m.db.Migrator().HasTable(&SchemaMigration{}) {
+		return nil
+	}
+	return m.db.Migrator().CreateTable(&SchemaMigration{})
+}
+
+func (m *Migrator) appliedMigrations() (map[uint]*SchemaMigration, error) {
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+	records := make([]*SchemaMigration, 0)
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[uint]*SchemaMigration, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// Up applies all pending migrations in version order and returns the applied ones.
+func (m *Migrator) Up() ([]*Migration, error) {
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	done := make([]*Migration, 0)
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:     migration.Version,
+				Name:        migration.Name,
+				AppliedTime: time.Now().Unix(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("failed to apply migration %d %s, err=%s", migration.Version, migration.Name, err.Error())
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the latest applied migrations, at most steps of them, and returns the reverted ones.
+func (m *Migrator) Down(steps int) ([]*Migration, error) {
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	done := make([]*Migration, 0)
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == nil {
+			return done, fmt.Errorf("migration %d %s can not be reverted", migration.Version, migration.Name)
+		}
+		err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Where("version = ?", migration.Version).Delete(&SchemaMigration{}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("failed to revert migration %d %s, err=%s", migration.Version, migration.Name, err.Error())
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Status returns every known migration and whether it has been applied.
+func (m *Migrator) Status() ([]*Status, error) {
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]*Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		s := &Status{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+		if r, ok := applied[migration.Version]; ok {
+			s.Applied = true
+			s.AppliedTime = r.AppliedTime
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// HasPending reports whether there are migrations which have not been applied yet.
+func (m *Migrator) HasPending() (bool, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return false, err
+	}
+	for _, s := range statuses {
+		if !s.Applied {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,99 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-relayer/db/dbtest"
+	"github.com/zkMeLabs/mechain-relayer/db/model"
+)
+
+func TestMigrateUpAndDown(t *testing.T) {
+	relayerDB := dbtest.NewSqliteDB(t)
+	migrator := NewMigrator(relayerDB)
+
+	pending, err := migrator.HasPending()
+	require.NoError(t, err)
+	require.True(t, pending)
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	require.Len(t, applied, len(Migrations))
+	require.True(t, relayerDB.Migrator().HasColumn(&model.BscRelayPackage{}, "ClaimTxHash"))
+	require.True(t, relayerDB.Migrator().HasIndex(&model.GreenfieldRelayTransaction{}, "idx_greenfield_relay_transaction_height_status"))
+	require.True(t, relayerDB.Migrator().HasTable(&model.VoteEvidence{}))
+
+	// applying again is a no-op
+	applied, err = migrator.Up()
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	reverted, err := migrator.Down(3)
+	require.NoError(t, err)
+	require.Len(t, reverted, 3)
+	require.Equal(t, uint(4), reverted[0].Version)
+	require.False(t, relayerDB.Migrator().HasTable(&model.VoteEvidence{}))
+	require.False(t, relayerDB.Migrator().HasColumn(&model.BscRelayPackage{}, "ClaimTxHash"))
+	require.False(t, relayerDB.Migrator().HasColumn(&model.GreenfieldRelayTransaction{}, "ClaimedTxHash"))
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.True(t, statuses[0].Applied)
+	require.False(t, statuses[1].Applied)
+	require.False(t, statuses[2].Applied)
+	require.False(t, statuses[3].Applied)
+
+	// the baseline is never reverted
+	reverted, err = migrator.Down(1)
+	require.Error(t, err)
+	require.Empty(t, reverted)
+	require.True(t, relayerDB.Migrator().HasTable(&model.BscBlock{}))
+
+	applied, err = migrator.Up()
+	require.NoError(t, err)
+	require.Len(t, applied, 3)
+	require.NoError(t, relayerDB.Create(&model.GreenfieldRelayTransaction{ClaimedTxHash: "0x1"}).Error)
+}
+
+func TestMigrateAgainAfterUnrecordedChange(t *testing.T) {
+	relayerDB := dbtest.NewSqliteDB(t)
+	migrator := NewMigrator(relayerDB)
+	_, err := migrator.Up()
+	require.NoError(t, err)
+
+	// the schema changes were committed but not their records, as after a failure on MySQL
+	require.NoError(t, relayerDB.Where("version > ?", 1).Delete(&SchemaMigration{}).Error)
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	require.Len(t, applied, len(Migrations)-1)
+	require.True(t, relayerDB.Migrator().HasColumn(&model.BscRelayPackage{}, "ClaimTxHash"))
+	require.True(t, relayerDB.Migrator().HasIndex(&model.GreenfieldRelayTransaction{}, "idx_greenfield_relay_transaction_height_status"))
+	require.True(t, relayerDB.Migrator().HasTable(&model.VoteEvidence{}))
+
+	// the schema changes were reverted but the records were not deleted
+	for _, m := range Migrations[1:] {
+		require.NoError(t, m.Down(relayerDB))
+	}
+	reverted, err := migrator.Down(len(Migrations) - 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, len(Migrations)-1)
+	require.False(t, relayerDB.Migrator().HasTable(&model.VoteEvidence{}))
+	require.False(t, relayerDB.Migrator().HasColumn(&model.BscRelayPackage{}, "ClaimTxHash"))
+}
+
+func TestMigrateExistingTables(t *testing.T) {
+	relayerDB := dbtest.NewSqliteDB(t)
+	// tables created before migrations were introduced, without the claim tx hash columns
+	require.NoError(t, relayerDB.Migrator().CreateTable(&bscBlockV1{}, &bscRelayPackageV1{}, &voteV1{}))
+	require.NoError(t, relayerDB.Create(&bscBlockV1{BlockHash: "0xa", ParentHash: "0x9", Height: 10}).Error)
+
+	_, err := NewMigrator(relayerDB).Up()
+	require.NoError(t, err)
+	require.True(t, relayerDB.Migrator().HasTable(&model.GreenfieldBlock{}))
+	require.True(t, relayerDB.Migrator().HasColumn(&model.BscRelayPackage{}, "ClaimTxHash"))
+
+	var block model.BscBlock
+	require.NoError(t, relayerDB.Where("height = ?", 10).Take(&block).Error)
+	require.Equal(t, "0xa", block.BlockHash)
+}
@@ -0,0 +1,107 @@
+package migration
+
+import (
+	"gorm.io/gorm"
+)
+
+// Migrations of the relayer DB, append new migrations with the next version and never edit released ones. Every step
+// of a migration checks the current schema before changing it, so it is safe to apply on a DB whose tables were created
+// before migrations were introduced, and to run again after it failed halfway on MySQL.
+var Migrations = []*Migration{
+	{
+		Version: 1,
+		Name:    "create_initial_tables",
+		Up: func(tx *gorm.DB) error {
+			return createTablesIfMissing(tx, &bscBlockV1{}, &bscRelayPackageV1{}, &greenfieldBlockV1{},
+				&greenfieldRelayTransactionV1{}, &syncLightBlockTransactionV1{}, &voteV1{})
+		},
+		// reverting the baseline would drop all relayer data
+		Down: nil,
+	},
+	{
+		Version: 2,
+		Name:    "add_claim_tx_hash_columns",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumnIfMissing(tx, &bscRelayPackageV2{}, "ClaimTxHash"); err != nil {
+				return err
+			}
+			return addColumnIfMissing(tx, &greenfieldRelayTransactionV2{}, "ClaimedTxHash")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := dropColumnIfExists(tx, &bscRelayPackageV2{}, "ClaimTxHash"); err != nil {
+				return err
+			}
+			return dropColumnIfExists(tx, &greenfieldRelayTransactionV2{}, "ClaimedTxHash")
+		},
+	},
+	{
+		// the index was created on height only because of a malformed tag in the model
+		Version: 3,
+		Name:    "add_status_to_greenfield_relay_transaction_height_index",
+		Up: func(tx *gorm.DB) error {
+			return recreateIndex(tx, &greenfieldRelayTransactionV3{}, "idx_greenfield_relay_transaction_height_status")
+		},
+		Down: func(tx *gorm.DB) error {
+			return recreateIndex(tx, &greenfieldRelayTransactionV2{}, "idx_greenfield_relay_transaction_height_status")
+		},
+	},
+	{
+		Version: 4,
+		Name:    "create_vote_evidence_table",
+		Up: func(tx *gorm.DB) error {
+			return createTablesIfMissing(tx, &voteEvidenceV4{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropTablesIfExist(tx, &voteEvidenceV4{})
+		},
+	},
+}
+
+func createTablesIfMissing(tx *gorm.DB, tables ...interface{}) error {
+	for _, table := range tables {
+		if tx.Migrator().HasTable(table) {
+			continue
+		}
+		if err := tx.Migrator().CreateTable(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dropTablesIfExist(tx *gorm.DB, tables ...interface{}) error {
+	for _, table := range tables {
+		if !tx.Migrator().HasTable(table) {
+			continue
+		}
+		if err := tx.Migrator().DropTable(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addColumnIfMissing(tx *gorm.DB, table interface{}, field string) error {
+	if tx.Migrator().HasColumn(table, field) {
+		return nil
+	}
+	return tx.Migrator().AddColumn(table, field)
+}
+
+func dropColumnIfExists(tx *gorm.DB, table interface{}, field string) error {
+	if !tx.Migrator().HasColumn(table, field) {
+		return nil
+	}
+	return tx.Migrator().DropColumn(table, field)
+}
+
+// recreateIndex replaces the index with the definition from the given table struct. Running it again recreates the
+// index the same way, also if it failed between dropping and creating it.
+func recreateIndex(tx *gorm.DB, table interface{}, name string) error {
+	if tx.Migrator().HasIndex(table, name) {
+		if err := tx.Migrator().DropIndex(table, name); err != nil {
+			return err
+		}
+	}
+	return tx.Migrator().CreateIndex(table, name)
+}
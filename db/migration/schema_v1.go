@@ -0,0 +1,98 @@
+package migration
+
+import (
+	"github.com/zkMeLabs/mechain-relayer/db"
+)
+
+// The structs below freeze the schema created by the first migration, later changes of the models in db/model must
+// be applied by new migrations instead of editing these structs.
+
+type bscBlockV1 struct {
+	Id         int64
+	BlockHash  string `gorm:"NOT NULL"`
+	ParentHash string `gorm:"NOT NULL"`
+	Height     uint64 `gorm:"NOT NULL;index:idx_bsc_block_height"`
+	BlockTime  int64  `gorm:"NOT NULL"`
+}
+
+func (*bscBlockV1) TableName() string {
+	return "bsc_block"
+}
+
+type bscRelayPackageV1 struct {
+	Id              int64
+	ChannelId       uint8       `gorm:"NOT NULL"`
+	OracleSequence  uint64      `gorm:"NOT NULL;index:idx_bsc_relay_package_oracle_sequence"`
+	PackageSequence uint64      `gorm:"NOT NULL"`
+	PayLoad         string      `gorm:"type:text"`
+	TxIndex         uint        `gorm:"NOT NULL"`
+	TxHash          string      `gorm:"NOT NULL"`
+	Height          uint64      `gorm:"NOT NULL;index:idx_bsc_relay_package_height_status"`
+	Status          db.TxStatus `gorm:"NOT NULL;index:idx_bsc_relay_package_height_status"`
+	TxTime          int64       `gorm:"NOT NULL"`
+	UpdatedTime     int64       `gorm:"NOT NULL"`
+}
+
+func (*bscRelayPackageV1) TableName() string {
+	return "bsc_relay_package"
+}
+
+type greenfieldBlockV1 struct {
+	Id        int64
+	Chain     string
+	Height    uint64 `gorm:"NOT NULL;index:idx_greenfield_block_height"`
+	BlockTime int64  `gorm:"NOT NULL"`
+}
+
+func (*greenfieldBlockV1) TableName() string {
+	return "greenfield_block"
+}
+
+type greenfieldRelayTransactionV1 struct {
+	Id            int64
+	SrcChainId    uint32 `gorm:"NOT NULL"`
+	DestChainId   uint32 `gorm:"NOT NULL"`
+	ChannelId     uint8  `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status"`
+	Sequence      uint64 `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status"`
+	PackageType   uint32 `gorm:"NOT NULL"`
+	Height        uint64 `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_height_status"`
+	PayLoad       string `gorm:"type:text"`
+	RelayerFee    string `gorm:"NOT NULL"`
+	AckRelayerFee string `gorm:"NOT NULL"`
+	TxHash        string
+	Status        db.TxStatus `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status"`
+	TxTime        int64       `gorm:"NOT NULL"`
+	UpdatedTime   int64       `gorm:"NOT NULL"`
+}
+
+func (*greenfieldRelayTransactionV1) TableName() string {
+	return "greenfield_relay_transaction"
+}
+
+type syncLightBlockTransactionV1 struct {
+	Id             int64
+	ValidatorsHash string `gorm:"NOT NULL"`
+	Height         uint64 `gorm:"NOT NULL;index:idx_sync_light_block_transaction_height"`
+	TxHash         string `gorm:"NOT NULL"`
+}
+
+func (*syncLightBlockTransactionV1) TableName() string {
+	return "sync_light_block_transaction"
+}
+
+type voteV1 struct {
+	Id           int64
+	Height       int64  `gorm:"NOT NULL;index:idx_vote_height"`
+	Signature    string `gorm:"NOT NULL"`
+	EventType    uint32 `gorm:"NOT NULL"`
+	ClaimPayload []byte `gorm:"NOT NULL"`
+	EventHash    []byte `gorm:"NOT NULL"`
+	Sequence     uint64 `gorm:"NOT NULL;uniqueIndex:idx_vote_channel_id_sequence_pub_key"`
+	ChannelId    uint8  `gorm:"NOT NULL;uniqueIndex:idx_vote_channel_id_sequence_pub_key"`
+	PubKey       string `gorm:"NOT NULL;uniqueIndex:idx_vote_channel_id_sequence_pub_key;size:256"`
+	CreatedTime  int64  `gorm:"NOT NULL"`
+}
+
+func (*voteV1) TableName() string {
+	return "vote"
+}
@@ -0,0 +1,48 @@
+package migration
+
+import (
+	"github.com/zkMeLabs/mechain-relayer/db"
+)
+
+// The structs below freeze the tables as changed by the second migration, which added the claim tx hash columns.
+
+type bscRelayPackageV2 struct {
+	Id              int64
+	ChannelId       uint8  `gorm:"NOT NULL"`
+	OracleSequence  uint64 `gorm:"NOT NULL;index:idx_bsc_relay_package_oracle_sequence"`
+	PackageSequence uint64 `gorm:"NOT NULL"`
+	PayLoad         string `gorm:"type:text"`
+	TxIndex         uint   `gorm:"NOT NULL"`
+	TxHash          string `gorm:"NOT NULL"`
+	ClaimTxHash     string
+	Height          uint64      `gorm:"NOT NULL;index:idx_bsc_relay_package_height_status"`
+	Status          db.TxStatus `gorm:"NOT NULL;index:idx_bsc_relay_package_height_status"`
+	TxTime          int64       `gorm:"NOT NULL"`
+	UpdatedTime     int64       `gorm:"NOT NULL"`
+}
+
+func (*bscRelayPackageV2) TableName() string {
+	return "bsc_relay_package"
+}
+
+type greenfieldRelayTransactionV2 struct {
+	Id            int64
+	SrcChainId    uint32 `gorm:"NOT NULL"`
+	DestChainId   uint32 `gorm:"NOT NULL"`
+	ChannelId     uint8  `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status"`
+	Sequence      uint64 `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status"`
+	PackageType   uint32 `gorm:"NOT NULL"`
+	Height        uint64 `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_height_status"`
+	PayLoad       string `gorm:"type:text"`
+	RelayerFee    string `gorm:"NOT NULL"`
+	AckRelayerFee string `gorm:"NOT NULL"`
+	TxHash        string
+	ClaimedTxHash string
+	Status        db.TxStatus `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status"`
+	TxTime        int64       `gorm:"NOT NULL"`
+	UpdatedTime   int64       `gorm:"NOT NULL"`
+}
+
+func (*greenfieldRelayTransactionV2) TableName() string {
+	return "greenfield_relay_transaction"
+}
@@ -0,0 +1,29 @@
+package migration
+
+import (
+	"github.com/zkMeLabs/mechain-relayer/db"
+)
+
+// greenfieldRelayTransactionV3 freezes the greenfield_relay_transaction table as changed by the third migration, which
+// added the status to the height index.
+type greenfieldRelayTransactionV3 struct {
+	Id            int64
+	SrcChainId    uint32 `gorm:"NOT NULL"`
+	DestChainId   uint32 `gorm:"NOT NULL"`
+	ChannelId     uint8  `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status"`
+	Sequence      uint64 `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status"`
+	PackageType   uint32 `gorm:"NOT NULL"`
+	Height        uint64 `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_height_status"`
+	PayLoad       string `gorm:"type:text"`
+	RelayerFee    string `gorm:"NOT NULL"`
+	AckRelayerFee string `gorm:"NOT NULL"`
+	TxHash        string
+	ClaimedTxHash string
+	Status        db.TxStatus `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status;index:idx_greenfield_relay_transaction_height_status"`
+	TxTime        int64       `gorm:"NOT NULL"`
+	UpdatedTime   int64       `gorm:"NOT NULL"`
+}
+
+func (*greenfieldRelayTransactionV3) TableName() string {
+	return "greenfield_relay_transaction"
+}
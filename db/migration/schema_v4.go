@@ -0,0 +1,21 @@
+package migration
+
+// voteEvidenceV4 freezes the vote_evidence table created by the fourth migration.
+type voteEvidenceV4 struct {
+	Id                   int64
+	EventType            uint32 `gorm:"NOT NULL;uniqueIndex:idx_vote_evidence_vote"`
+	ChannelId            uint8  `gorm:"NOT NULL;uniqueIndex:idx_vote_evidence_vote"`
+	Sequence             uint64 `gorm:"NOT NULL;uniqueIndex:idx_vote_evidence_vote"`
+	PubKey               string `gorm:"NOT NULL;uniqueIndex:idx_vote_evidence_vote;size:256"`
+	ConflictingEventHash string `gorm:"NOT NULL;uniqueIndex:idx_vote_evidence_vote;size:128"`
+	ConflictingSignature string `gorm:"NOT NULL"`
+	EventHash            string `gorm:"NOT NULL"`
+	LocalPubKey          string `gorm:"NOT NULL"`
+	LocalSignature       string `gorm:"NOT NULL"`
+	Height               int64  `gorm:"NOT NULL"`
+	CreatedTime          int64  `gorm:"NOT NULL;index:idx_vote_evidence_created_time"`
+}
+
+func (*voteEvidenceV4) TableName() string {
+	return "vote_evidence"
+}
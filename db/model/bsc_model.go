@@ -1,8 +1,6 @@
 package model
 
 import (
-	"gorm.io/gorm"
-
 	"github.com/zkMeLabs/mechain-relayer/db"
 )
 
@@ -36,19 +34,3 @@ type BscRelayPackage struct {
 func (l *BscRelayPackage) TableName() string {
 	return "bsc_relay_package"
 }
-
-func InitBSCTables(db *gorm.DB) {
-	if !db.Migrator().HasTable(&BscBlock{}) {
-		err := db.Migrator().CreateTable(&BscBlock{})
-		if err != nil {
-			panic(err)
-		}
-	}
-
-	if !db.Migrator().HasTable(&BscRelayPackage{}) {
-		err := db.Migrator().CreateTable(&BscRelayPackage{})
-		if err != nil {
-			panic(err)
-		}
-	}
-}
@@ -1,8 +1,6 @@
 package model
 
 import (
-	"gorm.io/gorm"
-
 	"github.com/zkMeLabs/mechain-relayer/db"
 )
 
@@ -30,7 +28,7 @@ type GreenfieldRelayTransaction struct {
 	AckRelayerFee string `gorm:"NOT NULL"`
 	TxHash        string
 	ClaimedTxHash string
-	Status        db.TxStatus `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status;idx_greenfield_relay_transaction_height_status"`
+	Status        db.TxStatus `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status;index:idx_greenfield_relay_transaction_height_status"`
 	TxTime        int64       `gorm:"NOT NULL"`
 	UpdatedTime   int64       `gorm:"NOT NULL"`
 }
@@ -49,26 +47,3 @@ type SyncLightBlockTransaction struct {
 func (*SyncLightBlockTransaction) TableName() string {
 	return "sync_light_block_transaction"
 }
-
-func InitGreenfieldTables(db *gorm.DB) {
-	if !db.Migrator().HasTable(&GreenfieldBlock{}) {
-		err := db.Migrator().CreateTable(&GreenfieldBlock{})
-		if err != nil {
-			panic(err)
-		}
-	}
-
-	if !db.Migrator().HasTable(&GreenfieldRelayTransaction{}) {
-		err := db.Migrator().CreateTable(&GreenfieldRelayTransaction{})
-		if err != nil {
-			panic(err)
-		}
-	}
-
-	if !db.Migrator().HasTable(&SyncLightBlockTransaction{}) {
-		err := db.Migrator().CreateTable(&SyncLightBlockTransaction{})
-		if err != nil {
-			panic(err)
-		}
-	}
-}
@@ -1,9 +1,5 @@
 package model
 
-import (
-	"gorm.io/gorm"
-)
-
 type Vote struct {
 	Id           int64
 	Height       int64  `gorm:"NOT NULL;index:idx_vote_height"`
@@ -21,11 +17,24 @@ func (*Vote) TableName() string {
 	return "vote"
 }
 
-func InitVoteTables(db *gorm.DB) {
-	if !db.Migrator().HasTable(&Vote{}) {
-		err := db.Migrator().CreateTable(&Vote{})
-		if err != nil {
-			panic(err)
-		}
-	}
+// VoteEvidence records a vote of a validator BLS key over an event hash other than the one computed locally for the
+// same event type, channel and sequence, together with the local vote over the expected event hash. Hashes, keys and
+// signatures are hex encoded.
+type VoteEvidence struct {
+	Id                   int64
+	EventType            uint32 `gorm:"NOT NULL;uniqueIndex:idx_vote_evidence_vote"`
+	ChannelId            uint8  `gorm:"NOT NULL;uniqueIndex:idx_vote_evidence_vote"`
+	Sequence             uint64 `gorm:"NOT NULL;uniqueIndex:idx_vote_evidence_vote"`
+	PubKey               string `gorm:"NOT NULL;uniqueIndex:idx_vote_evidence_vote;size:256"`
+	ConflictingEventHash string `gorm:"NOT NULL;uniqueIndex:idx_vote_evidence_vote;size:128"`
+	ConflictingSignature string `gorm:"NOT NULL"`
+	EventHash            string `gorm:"NOT NULL"`
+	LocalPubKey          string `gorm:"NOT NULL"`
+	LocalSignature       string `gorm:"NOT NULL"`
+	Height               int64  `gorm:"NOT NULL"`
+	CreatedTime          int64  `gorm:"NOT NULL;index:idx_vote_evidence_created_time"`
+}
+
+func (*VoteEvidence) TableName() string {
+	return "vote_evidence"
 }
@@ -3,10 +3,8 @@ package executor
 import (
 	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"encoding/binary"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -22,18 +20,20 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
-	"github.com/spf13/viper"
 
 	relayercommon "github.com/zkMeLabs/mechain-relayer/common"
 	"github.com/zkMeLabs/mechain-relayer/config"
 	"github.com/zkMeLabs/mechain-relayer/contract/crosschain"
 	"github.com/zkMeLabs/mechain-relayer/contract/greenfieldlightclient"
 	"github.com/zkMeLabs/mechain-relayer/contract/relayerhub"
+	"github.com/zkMeLabs/mechain-relayer/executor/gas"
+	"github.com/zkMeLabs/mechain-relayer/executor/nonce"
+	"github.com/zkMeLabs/mechain-relayer/keystore"
 	"github.com/zkMeLabs/mechain-relayer/logging"
 	"github.com/zkMeLabs/mechain-relayer/metric"
+	"github.com/zkMeLabs/mechain-relayer/signer"
 	rtypes "github.com/zkMeLabs/mechain-relayer/types"
 )
 
@@ -96,58 +96,53 @@ type BSCExecutor struct {
 	clientIdx          int
 	bscClients         []*BSCClient
 	config             *config.Config
-	privateKey         *ecdsa.PrivateKey
-	txSender           common.Address
+	keyMutex           sync.RWMutex
+	signer             signer.Signer
+	nonceManager       *nonce.Manager     // shared by every transaction sent by the relayer account
+	feeStrategy        gas.FeeStrategy    // prices the transactions, see bsc_config.fee_strategy
 	relayers           []rtypes.Validator // cached relayers
 	metricService      *metric.MetricService
 }
 
-func getBscPrivateKey(cfg *config.BSCConfig) string {
-	var privateKey string
-	if cfg.KeyType == config.KeyTypeAWSPrivateKey {
-		result, err := config.GetSecret(cfg.AWSSecretName, cfg.AWSRegion)
-		if err != nil {
-			panic(err)
-		}
-		type AwsPrivateKey struct {
-			PrivateKey string `json:"private_key"`
-		}
-		var awsPrivateKey AwsPrivateKey
-		err = json.Unmarshal([]byte(result), &awsPrivateKey)
-		if err != nil {
-			panic(err)
-		}
-		privateKey = awsPrivateKey.PrivateKey
-	} else {
-		privateKey = cfg.PrivateKey
+// getBscPrivateKey returns the private key read from the keystore file or the secret provider of the key type, or the
+// configured private key for the other key types.
+func getBscPrivateKey(cfg *config.Config) (string, error) {
+	bscCfg := &cfg.BSCConfig
+	if bscCfg.KeyType == config.KeyTypeLocalKeystore {
+		return getKeystorePrivateKey(keystore.DecryptECDSAKeyFile, bscCfg.KeystoreFile, bscCfg.KeystorePasswordFile)
 	}
-	return privateKey
+	return cfg.ResolveSecret(bscCfg.KeyType, bscCfg.AWSRegion, bscCfg.GetSecretName(), config.SecretFieldPrivateKey, bscCfg.PrivateKey)
 }
 
-func NewBSCExecutor(cfg *config.Config, metricService *metric.MetricService) *BSCExecutor {
-	privKey := viper.GetString(config.FlagConfigPrivateKey)
-	if privKey == "" {
-		privKey = getBscPrivateKey(&cfg.BSCConfig)
+// getBscSigner returns the signer of the configured key type.
+func getBscSigner(cfg *config.Config) (signer.Signer, error) {
+	bscCfg := &cfg.BSCConfig
+	if bscCfg.KeyType == config.KeyTypeRemoteSigner {
+		return signer.NewRemoteSigner(context.Background(), bscCfg.RemoteSignerAddr, common.HexToAddress(bscCfg.RemoteSignerAccount))
 	}
+	privKey, err := getBscPrivateKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return signer.NewLocalSignerFromHex(privKey)
+}
 
-	ecdsaPrivKey, err := crypto.HexToECDSA(privKey)
+func NewBSCExecutor(cfg *config.Config, metricService *metric.MetricService) *BSCExecutor {
+	bscSigner, err := getBscSigner(cfg)
 	if err != nil {
 		panic(err)
 	}
-	publicKey := ecdsaPrivKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		panic("get public key error")
-	}
-	txSender := crypto.PubkeyToAddress(*publicKeyECDSA)
-	return &BSCExecutor{
+	e := &BSCExecutor{
 		clientIdx:     0,
 		bscClients:    newBSCClients(cfg),
-		privateKey:    ecdsaPrivKey,
-		txSender:      txSender,
+		signer:        bscSigner,
 		config:        cfg,
 		metricService: metricService,
 	}
+	e.nonceManager = nonce.NewManager(e.GetNonce)
+	e.feeStrategy = newFeeStrategy(cfg.BSCConfig.GetFeeStrategy(), cfg.BSCConfig.GasPrice, cfg.BSCConfig.MaxGasPrice,
+		cfg.BSCConfig.GetFeeHistoryBlocks(), cfg.BSCConfig.GetPriorityFeePercentile(), cfg.BSCConfig.GetMaxPriorityFeePerGas())
+	return e
 }
 
 func DecodeConsensusState(input []byte) (ConsensusState, error) {
@@ -413,6 +408,18 @@ func (e *BSCExecutor) GetBlockHeaderAtHeight(height uint64) (*types.Header, erro
 	return header, nil
 }
 
+// DialWebsocketClient connects to the configured BSC websocket endpoint, which supports subscriptions
+func (e *BSCExecutor) DialWebsocketClient(ctx context.Context) (*ethclient.Client, error) {
+	if e.config.BSCConfig.WebsocketAddr == "" {
+		return nil, fmt.Errorf("websocket_addr is not configured")
+	}
+	client, err := ethclient.DialContext(ctx, e.config.BSCConfig.WebsocketAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial BSC websocket %s, err=%s", e.config.BSCConfig.WebsocketAddr, err.Error())
+	}
+	return client, nil
+}
+
 // GetNextReceiveSequenceForChannelWithRetry gets the next receive sequence for specified channel from BSC
 func (e *BSCExecutor) GetNextReceiveSequenceForChannelWithRetry(channelID rtypes.ChannelId) (sequence uint64, err error) {
 	return sequence, retry.Do(func() error {
@@ -485,24 +492,19 @@ func (e *BSCExecutor) getNextDeliveryOracleSequence(chainId sdk.ChainID) (uint64
 }
 
 func (e *BSCExecutor) getTransactor(nonce uint64) (*bind.TransactOpts, error) {
-	txOpts, err := bind.NewKeyedTransactorWithChainID(e.privateKey, big.NewInt(int64(e.config.BSCConfig.ChainId)))
-	if err != nil {
-		return nil, err
-	}
-	gasPrice, err := e.getGasPrice()
+	txOpts := signer.NewTransactor(context.Background(), e.getSigner(), big.NewInt(int64(e.config.BSCConfig.ChainId)))
+	fees, err := e.getFees()
 	if err != nil {
 		return nil, err
 	}
 	txOpts.Nonce = big.NewInt(int64(nonce))
 	txOpts.Value = big.NewInt(0)
 	txOpts.GasLimit = e.config.BSCConfig.GasLimit
-	txOpts.GasPrice = big.NewInt(gasPrice.Int64() + 1)
+	fees.Apply(txOpts)
 	return txOpts, nil
 }
 
 func (e *BSCExecutor) SyncTendermintLightBlock(height uint64) (common.Hash, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
-	defer cancel()
 	lightBlock, err := e.QueryTendermintLightBlockWithRetry(int64(height))
 	if err != nil {
 		return common.Hash{}, err
@@ -527,19 +529,9 @@ func (e *BSCExecutor) SyncTendermintLightBlock(height uint64) (common.Hash, erro
 	}
 	// logging.Logger.Debugf("validatorSetChanged: %t, new ConsensusStateBytes: %s", validatorSetChanged, hex.EncodeToString(consensusStateBytes))
 	result := EncodeLightBlockValidationResult(validatorSetChanged, consensusStateBytes)
-	nonce, err := e.GetEthClient().PendingNonceAt(ctx, e.txSender)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	txOpts, err := e.getTransactor(nonce)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	tx, err := e.GetGreenfieldLightClient().SyncLightBlock(txOpts, result, height)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	return tx.Hash(), nil
+	return e.sendTx(metric.BSCTxSyncLightBlock, func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+		return e.GetGreenfieldLightClient().SyncLightBlock(txOpts, result, height)
+	})
 }
 
 func (e *BSCExecutor) QueryTendermintLightBlockWithRetry(height int64) (lightBlock tmtypes.LightBlock, err error) {
@@ -578,29 +570,188 @@ func (e *BSCExecutor) QueryLatestTendermintHeaderWithRetry() (lightBlockBts []by
 func (e *BSCExecutor) GetNonce() (uint64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
 	defer cancel()
-	return e.GetEthClient().PendingNonceAt(ctx, e.txSender)
+	return e.GetEthClient().PendingNonceAt(ctx, e.GetAddress())
+}
+
+// GetTransactionReceipt returns the receipt of the tx, ethereum.NotFound is returned if the tx is not included yet.
+func (e *BSCExecutor) GetTransactionReceipt(txHash common.Hash) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+	defer cancel()
+	return e.GetEthClient().TransactionReceipt(ctx, txHash)
+}
+
+// IsTransactionDropped reports whether the tx is unknown to the RPC node, i.e. neither pending in its pool nor
+// included.
+func (e *BSCExecutor) IsTransactionDropped(txHash common.Hash) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+	defer cancel()
+	_, _, err := e.GetEthClient().TransactionByHash(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return true, nil
+	}
+	return false, err
+}
+
+// ResyncNonce makes the next tx read the nonce of the relayer account from chain, it is called when a sent tx is dropped.
+func (e *BSCExecutor) ResyncNonce() {
+	e.nonceManager.Resync()
 }
 
-func (e *BSCExecutor) CallBuildInSystemContract(blsSignature []byte, validatorSet *big.Int, msgBytes []byte, nonce uint64) (common.Hash, error) {
-	txOpts, err := e.getTransactor(nonce)
+func (e *BSCExecutor) CallBuildInSystemContract(blsSignature []byte, validatorSet *big.Int, msgBytes []byte) (common.Hash, error) {
+	return e.sendTx(metric.BSCTxHandlePackage, func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+		return e.getCrossChainClient().HandlePackage(txOpts, msgBytes, blsSignature, validatorSet)
+	})
+}
+
+// ReplaceTransaction resends a pending tx of the relayer account with the same nonce and bumped fees, so a tx stuck in
+// the mempool stops blocking the later nonces. The hash of the replacement is returned, gas.ErrGasPriceCeiling is
+// returned if max_gas_price does not allow to raise the gas price, or the max fee per gas of a dynamic fee tx, by
+// gas.MinBumpPercent.
+func (e *BSCExecutor) ReplaceTransaction(txHash common.Hash) (common.Hash, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+	defer cancel()
+	tx, isPending, err := e.GetEthClient().TransactionByHash(ctx, txHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !isPending {
+		return common.Hash{}, fmt.Errorf("tx %s is not pending", txHash.Hex())
+	}
+	chainId := big.NewInt(int64(e.config.BSCConfig.ChainId))
+	from, err := types.Sender(types.LatestSignerForChainID(chainId), tx)
 	if err != nil {
 		return common.Hash{}, err
 	}
+	if from != e.GetAddress() {
+		return common.Hash{}, fmt.Errorf("tx %s is not sent by the relayer account %s", txHash.Hex(), e.GetAddress().Hex())
+	}
+	current, err := e.feeStrategy.Fees(ctx, e.GetEthClient())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	fees, err := gas.ReplacementFees(tx, current, optionalBig(e.config.BSCConfig.MaxGasPrice),
+		e.config.BSCConfig.GetGasPriceBumpPercent())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var txData types.TxData
+	if fees.IsDynamic() {
+		txData = &types.DynamicFeeTx{
+			ChainID:   chainId,
+			Nonce:     tx.Nonce(),
+			GasTipCap: fees.GasTipCap,
+			GasFeeCap: fees.GasFeeCap,
+			Gas:       tx.Gas(),
+			To:        tx.To(),
+			Value:     tx.Value(),
+			Data:      tx.Data(),
+		}
+	} else {
+		txData = &types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: fees.GasPrice,
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}
+	}
+	replacement, err := e.getSigner().SignTx(ctx, types.NewTx(txData), chainId)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err = e.GetEthClient().SendTransaction(ctx, replacement); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to send replacement tx, nonce=%d, err=%s", tx.Nonce(), err.Error())
+	}
+	return replacement.Hash(), nil
+}
 
-	tx, err := e.getCrossChainClient().HandlePackage(txOpts, msgBytes, blsSignature, validatorSet)
+// sendTx sends a transaction of the relayer account with a nonce reserved from the nonce manager. The nonce is released
+// if the transaction is not sent, and the nonce manager is resynced from chain if the node rejected the nonce. A
+// transaction the node already knows is sent, its nonce is kept. The gas limit is the estimated gas with the margin of
+// bsc_config, the static gas_limit is used if the estimation fails.
+func (e *BSCExecutor) sendTx(method string, send func(txOpts *bind.TransactOpts) (*types.Transaction, error)) (common.Hash, error) {
+	n, err := e.nonceManager.Reserve()
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to reserve nonce, err=%s", err.Error())
+	}
+	txOpts, err := e.getTransactor(n)
 	if err != nil {
+		e.nonceManager.Release(n)
 		return common.Hash{}, err
 	}
+	estimated, err := e.estimateGas(method, txOpts, send)
+	if err != nil {
+		logging.Logger.Errorf("failed to estimate gas of %s tx, the static gas limit %d is used, err=%s", method, txOpts.GasLimit, err.Error())
+	} else {
+		cfg := &e.config.BSCConfig
+		txOpts.GasLimit = gas.LimitWithMargin(estimated, cfg.GetGasLimitMarginPercent(), cfg.GetMinGasLimit(), cfg.GetMaxGasLimit())
+	}
+	// the signed tx is kept, the node may reject it as already known if it was sent before
+	var signed *types.Transaction
+	signTx := txOpts.Signer
+	txOpts.Signer = func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		signedTx, err := signTx(addr, tx)
+		signed = signedTx
+		return signedTx, err
+	}
+	tx, err := send(txOpts)
+	if nonce.IsAlreadyKnown(err) && signed != nil {
+		logging.Logger.Infof("%s tx %s with nonce %d is already known by the node", method, signed.Hash().Hex(), n)
+		tx, err = signed, nil
+	}
+	if err != nil {
+		e.nonceManager.Release(n)
+		if nonce.IsNonceError(err) {
+			e.nonceManager.Resync()
+		}
+		return common.Hash{}, fmt.Errorf("failed to send tx, nonce=%d, err=%s", n, err.Error())
+	}
+	e.nonceManager.Commit(n)
 	return tx.Hash(), nil
 }
 
+// estimateGas returns the gas estimated for the transaction built by send. The transaction is built without being
+// signed or sent.
+func (e *BSCExecutor) estimateGas(method string, txOpts *bind.TransactOpts, send func(txOpts *bind.TransactOpts) (*types.Transaction, error)) (uint64, error) {
+	dryRunOpts := *txOpts
+	dryRunOpts.NoSend = true
+	dryRunOpts.Signer = func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		return tx, nil
+	}
+	tx, err := send(&dryRunOpts)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+	defer cancel()
+	estimated, err := e.GetEthClient().EstimateGas(ctx, ethereum.CallMsg{
+		From:  txOpts.From,
+		To:    tx.To(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if e.metricService != nil {
+		e.metricService.RecordBSCEstimatedGas(method, estimated)
+	}
+	return estimated, nil
+}
+
 // QueryLatestValidators used for gnfd -> bsc
 func (e *BSCExecutor) QueryLatestValidators() ([]rtypes.Validator, error) {
-	relayerAddresses, err := e.GetGreenfieldLightClient().GetRelayers(nil)
+	return queryLightClientRelayers(e.GetGreenfieldLightClient())
+}
+
+// queryLightClientRelayers returns the relayers registered in the light client contract with their BLS public keys.
+func queryLightClientRelayers(lightClient *greenfieldlightclient.Greenfieldlightclient) ([]rtypes.Validator, error) {
+	relayerAddresses, err := lightClient.GetRelayers(nil)
 	if err != nil {
 		return nil, err
 	}
-	blsKeys, err := e.GetGreenfieldLightClient().BlsPubKeys(nil)
+	blsKeys, err := lightClient.BlsPubKeys(nil)
 	if err != nil {
 		return nil, err
 	}
@@ -686,25 +837,13 @@ func (e *BSCExecutor) GetInturnRelayer() (*rtypes.InturnRelayer, error) {
 func (e *BSCExecutor) getRelayerBalance() (*big.Int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
 	defer cancel()
-	return e.GetEthClient().BalanceAt(ctx, e.txSender, nil)
+	return e.GetEthClient().BalanceAt(ctx, e.GetAddress(), nil)
 }
 
 func (e *BSCExecutor) claimReward() (common.Hash, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
-	defer cancel()
-	nonce, err := e.GetEthClient().PendingNonceAt(ctx, e.txSender)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	txOpts, err := e.getTransactor(nonce)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	txResp, err := e.getRelayerHub().ClaimReward(txOpts, e.txSender)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	return txResp.Hash(), nil
+	return e.sendTx(metric.BSCTxClaimReward, func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+		return e.getRelayerHub().ClaimReward(txOpts, e.GetAddress())
+	})
 }
 
 func (e *BSCExecutor) getRewardBalance() (*big.Int, error) {
@@ -714,7 +853,7 @@ func (e *BSCExecutor) getRewardBalance() (*big.Int, error) {
 		Pending: true,
 		Context: ctx,
 	}
-	return e.getRelayerHub().RewardMap(callOpts, e.txSender)
+	return e.getRelayerHub().RewardMap(callOpts, e.GetAddress())
 }
 
 // ClaimRewardLoop relayer would claim the reward if its balance is below 1BNB and the Reward is over 0.1BNB.
@@ -747,7 +886,9 @@ func (e *BSCExecutor) ClaimRewardLoop() {
 			continue
 		}
 		// > 0.1 BNB
+		e.BeginRelay()
 		txHash, err := e.claimReward()
+		e.EndRelay()
 		if err != nil {
 			logging.Logger.Errorf("failed to claim reward, txHash=%s, err=%s", txHash, err.Error())
 		}
@@ -767,20 +908,9 @@ func (e *BSCExecutor) getFinalizedBlockHeight(ctx context.Context, rpcClient *rp
 	return head.Number.Uint64(), nil
 }
 
-func (e *BSCExecutor) getGasPrice() (*big.Int, error) {
-	var (
-		gasPrice *big.Int
-		err      error
-	)
+// getFees returns the fees of the next tx, priced by the fee strategy of bsc_config.
+func (e *BSCExecutor) getFees() (*gas.Fees, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
 	defer cancel()
-	if e.config.BSCConfig.GasPrice == 0 {
-		gasPrice, err = e.GetEthClient().SuggestGasPrice(ctx)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		gasPrice = big.NewInt(int64(e.config.BSCConfig.GasPrice))
-	}
-	return gasPrice, nil
+	return e.feeStrategy.Fees(ctx, e.GetEthClient())
 }
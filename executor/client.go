@@ -11,6 +11,7 @@ import (
 
 	sdkclient "github.com/bnb-chain/greenfield-go-sdk/client"
 	"github.com/bnb-chain/greenfield-go-sdk/types"
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/zkMeLabs/mechain-relayer/contract/zkmecrosschainupgradeable"
@@ -19,6 +20,7 @@ import (
 
 type GreenfieldClient struct {
 	sdkclient.IClient
+	tmClient             *rpchttp.HTTP // queries the CometBFT RPC the sdk client has no call for
 	ethClient            *ethclient.Client
 	zkmeCrossChainClient *zkmecrosschainupgradeable.IZKMECrossChainUpgradeable
 	Height               int64
@@ -56,6 +58,12 @@ func NewGnfdCompositClients(rpcAddrs []string, chainId string, account *types.Ac
 			continue
 		}
 
+		tmClient, err := rpchttp.New(rpcAddrs[i], "/websocket")
+		if err != nil {
+			logging.Logger.Errorf("rpc node %s is not available", rpcAddrs[i])
+			continue
+		}
+
 		ethClient, err := ethclient.Dial(getEthRPCAddress(rpcAddrs[0]))
 		if err != nil {
 			panic("new eth client error")
@@ -68,6 +76,7 @@ func NewGnfdCompositClients(rpcAddrs []string, chainId string, account *types.Ac
 		}
 		clients = append(clients, &GreenfieldClient{
 			IClient:              sdkClient,
+			tmClient:             tmClient,
 			ethClient:            ethClient,
 			zkmeCrossChainClient: zkmeCrossChainClient,
 		})
@@ -119,3 +128,10 @@ func getClientBlockHeight(clientChan chan *GreenfieldClient, wg *sync.WaitGroup,
 	client.Height = latestHeight
 	clientChan <- client
 }
+
+// SetDefaultAccount sets the account which signs the transactions broadcast by all clients.
+func (gc *GnfdCompositeClients) SetDefaultAccount(account *types.Account) {
+	for _, c := range gc.clients {
+		c.SetDefaultAccount(account)
+	}
+}
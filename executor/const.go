@@ -18,6 +18,9 @@ const (
 	EstimatedTxExtraMetaSize       = 1024  // in bytes
 	GnfdGasPrice                   = int64(5000000000)
 	GasLimitRatio                  = int64(10)
+	PendingTxsPollInterval         = 2 * time.Second
+	ClaimSimulationBucketSize      = 4096 // in bytes, claims of a similar size share a simulation
+	ClaimSimulationTTL             = 10 * time.Minute
 )
 
 var (
@@ -0,0 +1,27 @@
+package executor
+
+import (
+	"math/big"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/executor/gas"
+)
+
+// newFeeStrategy returns the fee strategy named by strategy. Legacy txs are priced at gasPrice, or the gas price
+// suggested by the node if 0, eip1559 txs from the fee history. A cap of 0 is no cap.
+func newFeeStrategy(strategy string, gasPrice, maxGasPrice, feeHistoryBlocks, priorityFeePercentile, maxPriorityFeePerGas uint64) gas.FeeStrategy {
+	legacy := gas.NewLegacyStrategy(optionalBig(gasPrice), optionalBig(maxGasPrice))
+	if strategy != config.FeeStrategyEIP1559 {
+		return legacy
+	}
+	return gas.NewDynamicFeeStrategy(legacy, feeHistoryBlocks, priorityFeePercentile,
+		optionalBig(maxGasPrice), optionalBig(maxPriorityFeePerGas))
+}
+
+// optionalBig returns v as a big.Int, or nil if v is 0.
+func optionalBig(v uint64) *big.Int {
+	if v == 0 {
+		return nil
+	}
+	return new(big.Int).SetUint64(v)
+}
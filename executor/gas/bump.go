@@ -0,0 +1,38 @@
+package gas
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrGasPriceCeiling is returned when the ceiling does not leave room for a replacement the nodes accept.
+var ErrGasPriceCeiling = errors.New("gas price reached the ceiling")
+
+// MinBumpPercent is the least raise of the gas price of a replacement transaction accepted by the nodes.
+const MinBumpPercent = 10
+
+// BumpGasPrice returns the gas price of a transaction replacing one priced at price. The price is raised by percent and
+// at least to floor, e.g. the gas price currently suggested by the node, but never above ceiling, a nil ceiling is no
+// cap. ErrGasPriceCeiling is returned if a price raised by MinBumpPercent is above ceiling, the nodes would reject the
+// replacement.
+func BumpGasPrice(price, floor, ceiling *big.Int, percent uint64) (*big.Int, error) {
+	if ceiling != nil && raise(price, MinBumpPercent).Cmp(ceiling) > 0 {
+		return nil, ErrGasPriceCeiling
+	}
+	bumped := raise(price, percent)
+	if floor != nil && bumped.Cmp(floor) < 0 {
+		bumped.Set(floor)
+	}
+	return minBig(bumped, ceiling), nil
+}
+
+// raise returns price raised by percent, rounded up and by at least 1 wei.
+func raise(price *big.Int, percent uint64) *big.Int {
+	raised := new(big.Int).Mul(price, new(big.Int).SetUint64(100+percent))
+	raised.Add(raised, big.NewInt(99))
+	raised.Div(raised, big.NewInt(100))
+	if raised.Cmp(price) <= 0 {
+		raised.Add(price, big.NewInt(1))
+	}
+	return raised
+}
@@ -0,0 +1,42 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBumpGasPrice(t *testing.T) {
+	ceiling := big.NewInt(1000)
+
+	price, err := BumpGasPrice(big.NewInt(100), nil, ceiling, 20)
+	require.NoError(t, err)
+	require.Equal(t, int64(120), price.Int64())
+
+	// a tiny price is still raised
+	price, err = BumpGasPrice(big.NewInt(1), nil, ceiling, 20)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), price.Int64())
+
+	// the suggested price is used if it is higher
+	price, err = BumpGasPrice(big.NewInt(100), big.NewInt(300), ceiling, 20)
+	require.NoError(t, err)
+	require.Equal(t, int64(300), price.Int64())
+
+	price, err = BumpGasPrice(big.NewInt(900), nil, ceiling, 20)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), price.Int64())
+
+	_, err = BumpGasPrice(big.NewInt(1000), nil, ceiling, 20)
+	require.ErrorIs(t, err, ErrGasPriceCeiling)
+
+	// the ceiling leaves room for a raise of less than MinBumpPercent only
+	_, err = BumpGasPrice(big.NewInt(950), nil, ceiling, 20)
+	require.ErrorIs(t, err, ErrGasPriceCeiling)
+
+	// a raise of MinBumpPercent is rounded up
+	price, err = BumpGasPrice(big.NewInt(15), nil, ceiling, MinBumpPercent)
+	require.NoError(t, err)
+	require.Equal(t, int64(17), price.Int64())
+}
@@ -0,0 +1,162 @@
+package gas
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Client is the part of the eth client queried by the fee strategies, *ethclient.Client implements it.
+type Client interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// Fees are the fee fields of a transaction, GasPrice is set for a legacy transaction, GasFeeCap and GasTipCap for a
+// dynamic fee transaction.
+type Fees struct {
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+}
+
+// IsDynamic reports whether the fees are the ones of a dynamic fee transaction.
+func (f *Fees) IsDynamic() bool {
+	return f.GasFeeCap != nil
+}
+
+// Apply sets the fees to the transact options of the contract bindings.
+func (f *Fees) Apply(txOpts *bind.TransactOpts) {
+	txOpts.GasPrice = f.GasPrice
+	txOpts.GasFeeCap = f.GasFeeCap
+	txOpts.GasTipCap = f.GasTipCap
+}
+
+// FeeStrategy prices the transactions sent to a chain.
+type FeeStrategy interface {
+	Fees(ctx context.Context, client Client) (*Fees, error)
+}
+
+// LegacyStrategy prices legacy transactions with a fixed gas price, or with the gas price suggested by the node if no
+// fixed gas price is set, plus 1 wei. The gas price never exceeds maxGasPrice, a nil maxGasPrice is no cap.
+type LegacyStrategy struct {
+	gasPrice    *big.Int
+	maxGasPrice *big.Int
+}
+
+func NewLegacyStrategy(gasPrice, maxGasPrice *big.Int) *LegacyStrategy {
+	return &LegacyStrategy{
+		gasPrice:    gasPrice,
+		maxGasPrice: maxGasPrice,
+	}
+}
+
+func (s *LegacyStrategy) Fees(ctx context.Context, client Client) (*Fees, error) {
+	gasPrice := s.gasPrice
+	if gasPrice == nil {
+		suggested, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		gasPrice = suggested
+	}
+	return &Fees{GasPrice: minBig(new(big.Int).Add(gasPrice, big.NewInt(1)), s.maxGasPrice)}, nil
+}
+
+// DynamicFeeStrategy prices EIP-1559 transactions. The priority fee is the mean of the priorityFeePercentile percentile
+// of the priority fees paid in the last feeHistoryBlocks blocks, the max fee per gas is twice the base fee of the latest
+// block plus the priority fee, so the transaction stays includable while the base fee rises for a few blocks. Both are
+// capped to protect the relayer balance, a nil cap is no cap. Legacy transactions are priced by the fallback strategy
+// on chains without a base fee.
+type DynamicFeeStrategy struct {
+	fallback              *LegacyStrategy
+	feeHistoryBlocks      uint64
+	priorityFeePercentile float64
+	maxFeePerGas          *big.Int
+	maxPriorityFeePerGas  *big.Int
+}
+
+func NewDynamicFeeStrategy(fallback *LegacyStrategy, feeHistoryBlocks, priorityFeePercentile uint64,
+	maxFeePerGas, maxPriorityFeePerGas *big.Int,
+) *DynamicFeeStrategy {
+	return &DynamicFeeStrategy{
+		fallback:              fallback,
+		feeHistoryBlocks:      feeHistoryBlocks,
+		priorityFeePercentile: float64(priorityFeePercentile),
+		maxFeePerGas:          maxFeePerGas,
+		maxPriorityFeePerGas:  maxPriorityFeePerGas,
+	}
+}
+
+func (s *DynamicFeeStrategy) Fees(ctx context.Context, client Client) (*Fees, error) {
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if head.BaseFee == nil {
+		return s.fallback.Fees(ctx, client)
+	}
+	tip, err := s.priorityFee(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	tip = minBig(tip, s.maxPriorityFeePerGas)
+	feeCap := new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+	feeCap = minBig(feeCap.Add(feeCap, tip), s.maxFeePerGas)
+	return &Fees{GasFeeCap: feeCap, GasTipCap: minBig(tip, feeCap)}, nil
+}
+
+// priorityFee returns the priority fee from the fee history, or the one suggested by the node if the history is not
+// available or empty.
+func (s *DynamicFeeStrategy) priorityFee(ctx context.Context, client Client) (*big.Int, error) {
+	history, err := client.FeeHistory(ctx, s.feeHistoryBlocks, nil, []float64{s.priorityFeePercentile})
+	if err == nil {
+		sum, n := new(big.Int), int64(0)
+		for _, rewards := range history.Reward {
+			if len(rewards) == 0 || rewards[0] == nil {
+				continue
+			}
+			sum.Add(sum, rewards[0])
+			n++
+		}
+		if n > 0 {
+			return sum.Div(sum, big.NewInt(n)), nil
+		}
+	}
+	return client.SuggestGasTipCap(ctx)
+}
+
+// ReplacementFees returns the fees of a transaction replacing tx, both the gas price, or the max fee and the priority
+// fee of a dynamic fee transaction, are bumped by percent, at least to the fees of floor, and at most to ceiling, a nil
+// ceiling is no cap.
+func ReplacementFees(tx *types.Transaction, floor *Fees, ceiling *big.Int, percent uint64) (*Fees, error) {
+	if tx.Type() != types.DynamicFeeTxType {
+		gasPrice, err := BumpGasPrice(tx.GasPrice(), floor.GasPrice, ceiling, percent)
+		if err != nil {
+			return nil, err
+		}
+		return &Fees{GasPrice: gasPrice}, nil
+	}
+	feeCap, err := BumpGasPrice(tx.GasFeeCap(), floor.GasFeeCap, ceiling, percent)
+	if err != nil {
+		return nil, err
+	}
+	// the max fee is raised by at least MinBumpPercent, so is the priority fee if it is capped to the max fee
+	tip, err := BumpGasPrice(tx.GasTipCap(), floor.GasTipCap, feeCap, percent)
+	if err != nil {
+		tip = feeCap
+	}
+	return &Fees{GasFeeCap: feeCap, GasTipCap: tip}, nil
+}
+
+func minBig(x, y *big.Int) *big.Int {
+	if y != nil && x.Cmp(y) > 0 {
+		return new(big.Int).Set(y)
+	}
+	return x
+}
@@ -0,0 +1,114 @@
+package gas
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	baseFee  *big.Int
+	gasPrice *big.Int
+	tipCap   *big.Int
+	rewards  [][]*big.Int
+}
+
+func (c *fakeClient) HeaderByNumber(_ context.Context, _ *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: c.baseFee}, nil
+}
+
+func (c *fakeClient) SuggestGasPrice(_ context.Context) (*big.Int, error) {
+	return c.gasPrice, nil
+}
+
+func (c *fakeClient) SuggestGasTipCap(_ context.Context) (*big.Int, error) {
+	return c.tipCap, nil
+}
+
+func (c *fakeClient) FeeHistory(_ context.Context, _ uint64, _ *big.Int, _ []float64) (*ethereum.FeeHistory, error) {
+	if c.rewards == nil {
+		return nil, errors.New("fee history not supported")
+	}
+	return &ethereum.FeeHistory{Reward: c.rewards}, nil
+}
+
+func TestLegacyStrategy(t *testing.T) {
+	client := &fakeClient{gasPrice: big.NewInt(100)}
+
+	fees, err := NewLegacyStrategy(nil, big.NewInt(1000)).Fees(context.Background(), client)
+	require.NoError(t, err)
+	require.False(t, fees.IsDynamic())
+	require.Equal(t, int64(101), fees.GasPrice.Int64())
+
+	fees, err = NewLegacyStrategy(big.NewInt(2000), big.NewInt(1000)).Fees(context.Background(), client)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), fees.GasPrice.Int64())
+
+	// a fixed gas price is raised by 1 wei as well
+	fees, err = NewLegacyStrategy(big.NewInt(500), big.NewInt(1000)).Fees(context.Background(), client)
+	require.NoError(t, err)
+	require.Equal(t, int64(501), fees.GasPrice.Int64())
+
+	// without a cap
+	fees, err = NewLegacyStrategy(big.NewInt(2000), nil).Fees(context.Background(), client)
+	require.NoError(t, err)
+	require.Equal(t, int64(2001), fees.GasPrice.Int64())
+}
+
+func TestDynamicFeeStrategy(t *testing.T) {
+	fallback := NewLegacyStrategy(nil, big.NewInt(10000))
+	client := &fakeClient{
+		baseFee:  big.NewInt(100),
+		gasPrice: big.NewInt(150),
+		tipCap:   big.NewInt(7),
+		rewards:  [][]*big.Int{{big.NewInt(10)}, {}, {big.NewInt(20)}},
+	}
+
+	fees, err := NewDynamicFeeStrategy(fallback, 3, 50, big.NewInt(10000), big.NewInt(10000)).Fees(context.Background(), client)
+	require.NoError(t, err)
+	require.True(t, fees.IsDynamic())
+	require.Equal(t, int64(15), fees.GasTipCap.Int64())
+	require.Equal(t, int64(215), fees.GasFeeCap.Int64())
+
+	// the node suggestion is used without fee history, and the fees are capped
+	client.rewards = nil
+	fees, err = NewDynamicFeeStrategy(fallback, 3, 50, big.NewInt(150), big.NewInt(5)).Fees(context.Background(), client)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), fees.GasTipCap.Int64())
+	require.Equal(t, int64(150), fees.GasFeeCap.Int64())
+
+	// legacy transactions on chains without base fee
+	client.baseFee = nil
+	fees, err = NewDynamicFeeStrategy(fallback, 3, 50, big.NewInt(10000), big.NewInt(10000)).Fees(context.Background(), client)
+	require.NoError(t, err)
+	require.False(t, fees.IsDynamic())
+	require.Equal(t, int64(151), fees.GasPrice.Int64())
+}
+
+func TestReplacementFees(t *testing.T) {
+	to := common.HexToAddress("0x01")
+	dynamicTx := types.NewTx(&types.DynamicFeeTx{GasFeeCap: big.NewInt(200), GasTipCap: big.NewInt(200), To: &to})
+	fees, err := ReplacementFees(dynamicTx, &Fees{GasFeeCap: big.NewInt(100), GasTipCap: big.NewInt(10)}, big.NewInt(1000), 20)
+	require.NoError(t, err)
+	require.Equal(t, int64(240), fees.GasFeeCap.Int64())
+	require.Equal(t, int64(240), fees.GasTipCap.Int64())
+
+	legacyTx := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(100), To: &to})
+	fees, err = ReplacementFees(legacyTx, &Fees{GasFeeCap: big.NewInt(300), GasTipCap: big.NewInt(10)}, big.NewInt(1000), 20)
+	require.NoError(t, err)
+	require.Equal(t, int64(120), fees.GasPrice.Int64())
+
+	_, err = ReplacementFees(legacyTx, &Fees{GasPrice: big.NewInt(90)}, big.NewInt(100), 20)
+	require.ErrorIs(t, err, ErrGasPriceCeiling)
+
+	// without a ceiling
+	fees, err = ReplacementFees(legacyTx, &Fees{GasPrice: big.NewInt(90)}, nil, 20)
+	require.NoError(t, err)
+	require.Equal(t, int64(120), fees.GasPrice.Int64())
+}
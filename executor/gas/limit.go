@@ -0,0 +1,14 @@
+package gas
+
+// LimitWithMargin returns the gas limit of a transaction estimated to use estimated gas, raised by marginPercent and
+// kept within [floor, ceiling].
+func LimitWithMargin(estimated, marginPercent, floor, ceiling uint64) uint64 {
+	limit := estimated + estimated*marginPercent/100
+	if limit < floor {
+		limit = floor
+	}
+	if limit > ceiling {
+		limit = ceiling
+	}
+	return limit
+}
@@ -0,0 +1,13 @@
+package gas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitWithMargin(t *testing.T) {
+	require.Equal(t, uint64(120000), LimitWithMargin(100000, 20, 50000, 1000000))
+	require.Equal(t, uint64(50000), LimitWithMargin(21000, 20, 50000, 1000000))
+	require.Equal(t, uint64(1000000), LimitWithMargin(900000, 20, 50000, 1000000))
+}
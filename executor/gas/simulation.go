@@ -0,0 +1,71 @@
+package gas
+
+import (
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Simulation is the gas used by a simulated transaction with Msgs messages of PayloadSize bytes in total, and the min
+// gas price of the chain at the time of the simulation.
+type Simulation struct {
+	Msgs        int
+	PayloadSize int
+	GasUsed     uint64
+	GasPrice    *big.Int
+	Denom       string
+	simulatedAt time.Time
+}
+
+// SimulationCache keeps the latest simulation per number of messages and payload size bucket, so transactions of a
+// similar size are not simulated again before ttl passes. The gas used of a cached simulation is scaled up to the
+// payload size of the transaction it is reused for.
+type SimulationCache struct {
+	mutex      sync.Mutex
+	bucketSize int
+	ttl        time.Duration
+	entries    map[simulationKey]*Simulation
+}
+
+type simulationKey struct {
+	msgs   int
+	bucket int
+}
+
+func NewSimulationCache(bucketSize int, ttl time.Duration) *SimulationCache {
+	return &SimulationCache{
+		bucketSize: bucketSize,
+		ttl:        ttl,
+		entries:    make(map[simulationKey]*Simulation),
+	}
+}
+
+// Get returns the simulation of the bucket of msgs and payloadSize, if it is not expired.
+func (c *SimulationCache) Get(msgs, payloadSize int) (*Simulation, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	s, ok := c.entries[simulationKey{msgs: msgs, bucket: payloadSize / c.bucketSize}]
+	if !ok || time.Since(s.simulatedAt) >= c.ttl {
+		return nil, false
+	}
+	gasUsed := s.GasUsed
+	if payloadSize > s.PayloadSize && s.PayloadSize > 0 {
+		gasUsed = s.GasUsed * uint64(payloadSize) / uint64(s.PayloadSize)
+	}
+	return &Simulation{
+		Msgs:        msgs,
+		PayloadSize: payloadSize,
+		GasUsed:     gasUsed,
+		GasPrice:    s.GasPrice,
+		Denom:       s.Denom,
+		simulatedAt: s.simulatedAt,
+	}, true
+}
+
+// Put stores the simulation as the one of its bucket.
+func (c *SimulationCache) Put(s *Simulation) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	s.simulatedAt = time.Now()
+	c.entries[simulationKey{msgs: s.Msgs, bucket: s.PayloadSize / c.bucketSize}] = s
+}
@@ -0,0 +1,36 @@
+package gas
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulationCache(t *testing.T) {
+	c := NewSimulationCache(1024, time.Minute)
+	_, ok := c.Get(1, 100)
+	require.False(t, ok)
+
+	c.Put(&Simulation{Msgs: 1, PayloadSize: 200, GasUsed: 1000, GasPrice: big.NewInt(5), Denom: "azkme"})
+	s, ok := c.Get(1, 100)
+	require.True(t, ok)
+	require.Equal(t, uint64(1000), s.GasUsed)
+	require.Equal(t, "azkme", s.Denom)
+
+	// the gas used is scaled up for a larger payload of the same bucket
+	s, ok = c.Get(1, 400)
+	require.True(t, ok)
+	require.Equal(t, uint64(2000), s.GasUsed)
+
+	_, ok = c.Get(1, 2048)
+	require.False(t, ok)
+	_, ok = c.Get(2, 200)
+	require.False(t, ok)
+
+	c = NewSimulationCache(1024, 0)
+	c.Put(&Simulation{Msgs: 1, PayloadSize: 200, GasUsed: 1000, GasPrice: big.NewInt(5)})
+	_, ok = c.Get(1, 200)
+	require.False(t, ok)
+}
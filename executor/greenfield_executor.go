@@ -2,88 +2,111 @@ package executor
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/0xPolygon/polygon-edge/bls"
 	"github.com/avast/retry-go/v4"
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 	tmtypes "github.com/cometbft/cometbft/types"
 	"github.com/cometbft/cometbft/votepool"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkErrors "github.com/cosmos/cosmos-sdk/types/errors"
 	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/spf13/viper"
 
 	sdktypes "github.com/bnb-chain/greenfield-go-sdk/types"
 	gnfdsdktypes "github.com/evmos/evmos/v12/sdk/types"
 	relayercommon "github.com/zkMeLabs/mechain-relayer/common"
 	"github.com/zkMeLabs/mechain-relayer/config"
 	"github.com/zkMeLabs/mechain-relayer/contract/zkmecrosschainupgradeable"
+	"github.com/zkMeLabs/mechain-relayer/executor/gas"
+	"github.com/zkMeLabs/mechain-relayer/keystore"
 	"github.com/zkMeLabs/mechain-relayer/logging"
+	"github.com/zkMeLabs/mechain-relayer/signer"
 	"github.com/zkMeLabs/mechain-relayer/types"
 )
 
 type GreenfieldExecutor struct {
-	mutex         sync.RWMutex
-	BscExecutor   *BSCExecutor
-	gnfdClients   GnfdCompositeClients
-	config        *config.Config
-	privateKey    *ecdsa.PrivateKey
-	address       string
-	validators    []*tmtypes.Validator // used to cache validators
-	BlsPrivateKey []byte
-	BlsPubKey     []byte
-}
-
-func NewGreenfieldExecutor(cfg *config.Config) *GreenfieldExecutor {
-	privKey := viper.GetString(config.FlagConfigPrivateKey)
-	if privKey == "" {
-		privKey = getGreenfieldPrivateKey(&cfg.GreenfieldConfig)
+	mutex            sync.RWMutex
+	BscExecutor      *BSCExecutor
+	gnfdClients      GnfdCompositeClients
+	config           *config.Config
+	keyMutex         sync.RWMutex
+	keys             *greenfieldKeys
+	relayGate        signer.Gate          // entered by every path signing with the relayer keys, see BeginRelay
+	validators       []*tmtypes.Validator // used to cache validators
+	feeStrategy      gas.FeeStrategy      // prices the transactions sent to the Mechain EVM
+	claimSimulations *gas.SimulationCache // simulations of the claim txs by payload size
+}
+
+// greenfieldKeys are the keys of the relayer on Mechain, they are swapped as a whole when the keys are rotated.
+type greenfieldKeys struct {
+	account       *sdktypes.Account
+	evmSigner     signer.Signer // signs the transactions sent to the Greenfield EVM
+	blsPrivateKey []byte
+	blsPubKey     []byte
+}
+
+func newGreenfieldKeys(privKey string, blsPrivKey []byte) (*greenfieldKeys, error) {
+	evmSigner, err := signer.NewLocalSignerFromHex(privKey)
+	if err != nil {
+		return nil, err
 	}
-	ecdsaPrivKey, err := crypto.HexToECDSA(privKey)
+	blsKey, err := bls.UnmarshalPrivateKey(blsPrivKey)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to load bls private key, err=%s", err.Error())
 	}
-	blsPrivKeyStr := viper.GetString(config.FlagConfigBlsPrivateKey)
-	if blsPrivKeyStr == "" {
-		blsPrivKeyStr = getGreenfieldBlsPrivateKey(&cfg.GreenfieldConfig)
+	account, err := sdktypes.NewAccountFromPrivateKey("relayer", privKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load greenfield private key, err=%s", err.Error())
 	}
-	blsPrivKeyBts, err := hex.DecodeString(blsPrivKeyStr)
+	return &greenfieldKeys{
+		account:       account,
+		evmSigner:     evmSigner,
+		blsPrivateKey: blsPrivKey,
+		blsPubKey:     blsKey.PublicKey().Marshal(),
+	}, nil
+}
+
+func NewGreenfieldExecutor(cfg *config.Config) *GreenfieldExecutor {
+	privKey, err := getGreenfieldPrivateKey(cfg)
 	if err != nil {
 		panic(err)
 	}
-	blsPrivKey, err := bls.UnmarshalPrivateKey(blsPrivKeyBts)
+	blsPrivKey, err := getGreenfieldBlsPrivateKeyBytes(cfg)
 	if err != nil {
 		panic(err)
 	}
-	account, err := sdktypes.NewAccountFromPrivateKey("relayer", privKey)
+	keys, err := newGreenfieldKeys(privKey, blsPrivKey)
 	if err != nil {
 		panic(err)
 	}
 	clients := NewGnfdCompositClients(
 		cfg.GreenfieldConfig.RPCAddrs,
 		cfg.GreenfieldConfig.ChainIdString,
-		account,
+		keys.account,
 		cfg.GreenfieldConfig.UseWebsocket,
 		cfg.RelayConfig.SrcZkmeSBTContractAddr,
 	)
+	gnfdCfg := &cfg.GreenfieldConfig
 	return &GreenfieldExecutor{
-		gnfdClients:   clients,
-		address:       account.GetAddress().String(),
-		config:        cfg,
-		privateKey:    ecdsaPrivKey,
-		BlsPrivateKey: blsPrivKeyBts,
-		BlsPubKey:     blsPrivKey.PublicKey().Marshal(),
+		gnfdClients: clients,
+		config:      cfg,
+		keys:        keys,
+		feeStrategy: newFeeStrategy(gnfdCfg.GetFeeStrategy(), gnfdCfg.EvmGasPrice, gnfdCfg.MaxGasPrice,
+			gnfdCfg.GetFeeHistoryBlocks(), gnfdCfg.GetPriorityFeePercentile(), gnfdCfg.GetMaxPriorityFeePerGas()),
+		claimSimulations: gas.NewSimulationCache(ClaimSimulationBucketSize, ClaimSimulationTTL),
 	}
 }
 
@@ -91,42 +114,37 @@ func (e *GreenfieldExecutor) SetBSCExecutor(be *BSCExecutor) {
 	e.BscExecutor = be
 }
 
-func getGreenfieldPrivateKey(cfg *config.GreenfieldConfig) string {
-	if cfg.KeyType == config.KeyTypeAWSPrivateKey {
-		result, err := config.GetSecret(cfg.AWSSecretName, cfg.AWSRegion)
-		if err != nil {
-			panic(err)
-		}
-		type AwsPrivateKey struct {
-			PrivateKey string `json:"private_key"`
-		}
-		var awsPrivateKey AwsPrivateKey
-		err = json.Unmarshal([]byte(result), &awsPrivateKey)
-		if err != nil {
-			panic(err)
-		}
-		return awsPrivateKey.PrivateKey
+// getGreenfieldPrivateKey returns the private key read from the keystore file or the secret provider of the key type, or
+// the configured private key for the other key types.
+func getGreenfieldPrivateKey(cfg *config.Config) (string, error) {
+	gnfdCfg := &cfg.GreenfieldConfig
+	if gnfdCfg.KeyType == config.KeyTypeLocalKeystore {
+		return getKeystorePrivateKey(keystore.DecryptECDSAKeyFile, gnfdCfg.KeystoreFile, gnfdCfg.KeystorePasswordFile)
 	}
-	return cfg.PrivateKey
+	return cfg.ResolveSecret(gnfdCfg.KeyType, gnfdCfg.AWSRegion, gnfdCfg.GetSecretName(), config.SecretFieldPrivateKey, gnfdCfg.PrivateKey)
 }
 
-func getGreenfieldBlsPrivateKey(cfg *config.GreenfieldConfig) string {
-	if cfg.KeyType == config.KeyTypeAWSPrivateKey {
-		result, err := config.GetSecret(cfg.AWSBlsSecretName, cfg.AWSRegion)
-		if err != nil {
-			panic(err)
-		}
-		type AwsPrivateKey struct {
-			PrivateKey string `json:"bls_private_key"`
-		}
-		var awsBlsPrivateKey AwsPrivateKey
-		err = json.Unmarshal([]byte(result), &awsBlsPrivateKey)
-		if err != nil {
-			panic(err)
-		}
-		return awsBlsPrivateKey.PrivateKey
+// getGreenfieldBlsPrivateKey returns the BLS private key read from the EIP-2335 keystore file or the secret provider of
+// the key type, or the configured BLS private key for the other key types.
+func getGreenfieldBlsPrivateKey(cfg *config.Config) (string, error) {
+	gnfdCfg := &cfg.GreenfieldConfig
+	if gnfdCfg.KeyType == config.KeyTypeLocalKeystore {
+		return getKeystorePrivateKey(keystore.DecryptBLSKeyFile, gnfdCfg.BlsKeystoreFile, gnfdCfg.KeystorePasswordFile)
 	}
-	return cfg.BlsPrivateKey
+	return cfg.ResolveSecret(gnfdCfg.KeyType, gnfdCfg.AWSRegion, gnfdCfg.GetBlsSecretName(), config.SecretFieldBlsPrivateKey,
+		gnfdCfg.BlsPrivateKey)
+}
+
+func getGreenfieldBlsPrivateKeyBytes(cfg *config.Config) ([]byte, error) {
+	blsPrivKey, err := getGreenfieldBlsPrivateKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	blsPrivKeyBts, err := hex.DecodeString(strings.TrimPrefix(blsPrivKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bls private key, err=%s", err.Error())
+	}
+	return blsPrivKeyBts, nil
 }
 
 func (e *GreenfieldExecutor) GetGnfdClient() *GreenfieldClient {
@@ -159,6 +177,28 @@ func (e *GreenfieldExecutor) GetBlockAndBlockResultAtHeight(height int64) (*tmty
 	return block, blockResults, nil
 }
 
+// NewEventClient starts a CometBFT websocket client on the first available RPC node for event subscriptions. The caller
+// has to stop the client.
+func (e *GreenfieldExecutor) NewEventClient() (*rpchttp.HTTP, error) {
+	var lastErr error
+	for _, addr := range e.config.GreenfieldConfig.RPCAddrs {
+		client, err := rpchttp.New(addr, "/websocket")
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err = client.Start(); err != nil {
+			lastErr = err
+			continue
+		}
+		return client, nil
+	}
+	if lastErr == nil {
+		return nil, fmt.Errorf("no Greenfield rpc address is configured")
+	}
+	return nil, fmt.Errorf("failed to start Greenfield websocket client, err=%s", lastErr.Error())
+}
+
 func (e *GreenfieldExecutor) GetLatestBlockHeight() (latestHeight uint64, err error) {
 	return uint64(e.gnfdClients.GetClient().Height), nil
 }
@@ -323,7 +363,7 @@ func (e *GreenfieldExecutor) GetValidatorsBlsPublicKey() ([]string, error) {
 func (e *GreenfieldExecutor) GetNonce() (uint64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
 	defer cancel()
-	acc, err := e.GetGnfdClient().GetAccount(ctx, e.address)
+	acc, err := e.GetGnfdClient().GetAccount(ctx, e.GetAddress())
 	if err != nil {
 		return 0, err
 	}
@@ -340,38 +380,43 @@ func (e *GreenfieldExecutor) GetNonceOnNextBlock() (uint64, error) {
 	return e.GetNonce()
 }
 
-func (e *GreenfieldExecutor) getGasPrice() (*big.Int, error) {
-	var (
-		gasPrice *big.Int
-		err      error
-	)
+// getFees returns the fees of the next tx sent to the Mechain EVM, priced by the fee strategy of mechain_config.
+func (e *GreenfieldExecutor) getFees() (*gas.Fees, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
 	defer cancel()
-	if e.config.BSCConfig.GasPrice == 0 {
-		gasPrice, err = e.GetEthClient().SuggestGasPrice(ctx)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		gasPrice = big.NewInt(int64(e.config.BSCConfig.GasPrice))
-	}
-	return gasPrice, nil
+	return e.feeStrategy.Fees(ctx, e.GetEthClient())
 }
 
-// TODO
-func (e *GreenfieldExecutor) getTransactor(nonce uint64) (*bind.TransactOpts, error) {
-	txOpts, err := bind.NewKeyedTransactorWithChainID(e.privateKey, big.NewInt(int64(e.config.GreenfieldConfig.ChainId)))
+// GetTxResult returns the result of the tx, nil is returned if the tx is not included yet. It does not wait for the tx.
+func (e *GreenfieldExecutor) GetTxResult(txHash string) (*ctypes.ResultTx, error) {
+	hash, err := hex.DecodeString(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tx hash %s, err=%s", txHash, err.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+	defer cancel()
+	res, err := e.GetGnfdClient().tmClient.Tx(ctx, hash, false)
 	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
 		return nil, err
 	}
-	gasPrice, err := e.getGasPrice()
+	return res, nil
+}
+
+// TODO: the gas limit of the Mechain EVM txs is the gas_limit of bsc_config, the gas_limit of mechain_config is the one
+// of the cosmos claim txs
+func (e *GreenfieldExecutor) getTransactor(nonce uint64) (*bind.TransactOpts, error) {
+	txOpts := signer.NewTransactor(context.Background(), e.getKeys().evmSigner, big.NewInt(int64(e.config.GreenfieldConfig.ChainId)))
+	fees, err := e.getFees()
 	if err != nil {
 		return nil, err
 	}
 	txOpts.Nonce = big.NewInt(int64(nonce))
 	txOpts.Value = big.NewInt(0)
 	txOpts.GasLimit = e.config.BSCConfig.GasLimit
-	txOpts.GasPrice = big.NewInt(gasPrice.Int64() + 1)
+	fees.Apply(txOpts)
 	return txOpts, nil
 }
 
@@ -388,29 +433,76 @@ func (e *GreenfieldExecutor) CallZkmeSBTAckMintedContract(chainId uint32, user e
 	return tx.Hash(), nil
 }
 
-func (e *GreenfieldExecutor) ClaimPackages(client *GreenfieldClient, payloadBts []byte, aggregatedSig []byte, voteAddressSet []uint64, claimTs int64, oracleSeq uint64, nonce uint64) (string, error) {
-	msg := oracletypes.NewMsgClaim(
-		e.address,
+// GetZkmeSBTCrossChainStatus returns the cross chain status of the zkmesbt of the user for the chain, as set by AckMinted.
+func (e *GreenfieldExecutor) GetZkmeSBTCrossChainStatus(chainId uint32, user ethcommon.Address) (uint8, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+	defer cancel()
+	return e.getCrossChainClient().GetCrossChainStatus(&bind.CallOpts{Context: ctx}, chainId, user)
+}
+
+// WaitForEvmTxReceipt waits until the tx sent to the Mechain EVM is included and returns its receipt.
+func (e *GreenfieldExecutor) WaitForEvmTxReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
+	for {
+		receipt, err := e.GetEthClient().TransactionReceipt(ctx, txHash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("tx %s is not included, err=%s", txHash.Hex(), ctx.Err().Error())
+		case <-time.After(PendingTxsPollInterval):
+		}
+	}
+}
+
+// OracleClaim is the claim of the packages of one oracle sequence.
+type OracleClaim struct {
+	Payload        []byte
+	AggregatedSig  []byte
+	VoteAddressSet []uint64
+	ClaimTs        int64
+	OracleSeq      uint64
+}
+
+// NewClaimMsg returns the MsgClaim of the claim sent by the relayer.
+func (e *GreenfieldExecutor) NewClaimMsg(c *OracleClaim) *oracletypes.MsgClaim {
+	return oracletypes.NewMsgClaim(
+		e.GetAddress(),
 		e.getSrcChainId(),
 		e.getDestChainId(),
-		oracleSeq,
-		uint64(claimTs),
-		payloadBts,
-		voteAddressSet,
-		aggregatedSig)
-	gasLimit, feeAmount, err := e.getGasLimitAndFeeAmount(msg)
+		c.OracleSeq,
+		uint64(c.ClaimTs),
+		c.Payload,
+		c.VoteAddressSet,
+		c.AggregatedSig)
+}
+
+// ClaimPackages broadcasts the claims of consecutive oracle sequences as the messages of one tx, in the order of
+// the sequences. The messages of a tx are executed atomically, the tx fails if any of the claims fails.
+func (e *GreenfieldExecutor) ClaimPackages(client *GreenfieldClient, claims []*OracleClaim, nonce uint64) (string, error) {
+	msgs := make([]*oracletypes.MsgClaim, 0, len(claims))
+	sdkMsgs := make([]sdk.Msg, 0, len(claims))
+	for _, c := range claims {
+		msg := e.NewClaimMsg(c)
+		msgs = append(msgs, msg)
+		sdkMsgs = append(sdkMsgs, msg)
+	}
+	gasLimit, feeAmount, err := e.getClaimGasLimitAndFee(client, msgs, nonce)
 	if err != nil {
 		return "", err
 	}
 	txOpt := gnfdsdktypes.TxOption{
 		NoSimulate: true,
-		GasLimit:   uint64(gasLimit),
-		FeeAmount:  sdk.NewCoins(sdk.NewCoin(gnfdsdktypes.Denom, sdk.NewInt(feeAmount))),
+		GasLimit:   gasLimit,
+		FeeAmount:  feeAmount,
 		Nonce:      nonce,
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
 	defer cancel()
-	resp, err := client.BroadcastTx(ctx, []sdk.Msg{msg}, &txOpt)
+	resp, err := client.BroadcastTx(ctx, sdkMsgs, &txOpt)
 	if err != nil {
 		return "", err
 	}
@@ -433,6 +525,15 @@ func (e *GreenfieldExecutor) ClaimPackages(client *GreenfieldClient, payloadBts
 	return txRes.TxHash, nil
 }
 
+// EstimateClaimGas returns the gas limit of a claim tx with the single msg, as used when the claim is not simulated.
+func (e *GreenfieldExecutor) EstimateClaimGas(msg *oracletypes.MsgClaim) (uint64, error) {
+	gasLimit, _, err := e.getGasLimitAndFeeAmount(msg)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(gasLimit), nil
+}
+
 func (e *GreenfieldExecutor) GetInturnRelayer(srcChain oracletypes.ClaimSrcChain) (*oracletypes.QueryInturnRelayerResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
 	defer cancel()
@@ -465,6 +566,67 @@ func (e *GreenfieldExecutor) getSrcChainId() uint32 {
 	return uint32(e.config.BSCConfig.ChainId)
 }
 
+// getClaimGasLimitAndFee returns the gas limit and the fee of a claim tx. With simulate_claim the gas limit is the gas
+// used by a simulation of the tx with the margin of mechain_config, priced by the min gas price returned by the
+// simulation. A simulation of a claim tx with as many msgs in the same payload size bucket is reused for
+// ClaimSimulationTTL. The static gas limits and fees of the msgs are added up if the simulation fails.
+func (e *GreenfieldExecutor) getClaimGasLimitAndFee(client *GreenfieldClient, msgs []*oracletypes.MsgClaim, nonce uint64) (uint64, sdk.Coins, error) {
+	if e.config.GreenfieldConfig.SimulateClaim {
+		sim, err := e.simulateClaim(client, msgs, nonce)
+		if err == nil {
+			gasLimit := sim.GasUsed + sim.GasUsed*e.config.GreenfieldConfig.GetGasLimitMarginPercent()/100
+			fee := new(big.Int).Mul(sim.GasPrice, new(big.Int).SetUint64(gasLimit))
+			return gasLimit, sdk.NewCoins(sdk.NewCoin(sim.Denom, sdk.NewIntFromBigInt(fee))), nil
+		}
+		logging.Logger.Errorf("failed to simulate claim tx, the static gas limit and fee are used, err=%s", err.Error())
+	}
+	var totalGasLimit, totalFeeAmount int64
+	for _, msg := range msgs {
+		gasLimit, feeAmount, err := e.getGasLimitAndFeeAmount(msg)
+		if err != nil {
+			return 0, nil, err
+		}
+		totalGasLimit += gasLimit
+		totalFeeAmount += feeAmount
+	}
+	return uint64(totalGasLimit), sdk.NewCoins(sdk.NewCoin(gnfdsdktypes.Denom, sdk.NewInt(totalFeeAmount))), nil
+}
+
+func (e *GreenfieldExecutor) simulateClaim(client *GreenfieldClient, msgs []*oracletypes.MsgClaim, nonce uint64) (*gas.Simulation, error) {
+	size := 0
+	sdkMsgs := make([]sdk.Msg, 0, len(msgs))
+	for _, msg := range msgs {
+		size += msg.Size()
+		sdkMsgs = append(sdkMsgs, msg)
+	}
+	if sim, ok := e.claimSimulations.Get(len(msgs), size); ok {
+		return sim, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+	defer cancel()
+	res, err := client.SimulateTx(ctx, sdkMsgs, gnfdsdktypes.TxOption{Nonce: nonce})
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := sdk.ParseCoinNormalized(res.GasInfo.GetMinGasPrice())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse min gas price %q, err=%s", res.GasInfo.GetMinGasPrice(), err.Error())
+	}
+	if gasPrice.IsNil() || gasPrice.IsZero() {
+		return nil, fmt.Errorf("invalid min gas price %q", res.GasInfo.GetMinGasPrice())
+	}
+	sim := &gas.Simulation{
+		Msgs:        len(msgs),
+		PayloadSize: size,
+		GasUsed:     res.GasInfo.GetGasUsed(),
+		GasPrice:    gasPrice.Amount.BigInt(),
+		Denom:       gasPrice.Denom,
+	}
+	e.claimSimulations.Put(sim)
+	logging.Logger.Infof("simulated claim tx with %d msgs of %d bytes, gas used %d, min gas price %s", len(msgs), size, sim.GasUsed, gasPrice.String())
+	return sim, nil
+}
+
 func (e *GreenfieldExecutor) getGasLimitAndFeeAmount(msg *oracletypes.MsgClaim) (gasLimit int64, feeAmount int64, err error) {
 	bz, err := msg.Marshal()
 	if err != nil {
@@ -0,0 +1,332 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/bls"
+	sdktypes "github.com/bnb-chain/greenfield-go-sdk/types"
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	tmtypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/contract/greenfieldlightclient"
+	"github.com/zkMeLabs/mechain-relayer/keystore"
+	"github.com/zkMeLabs/mechain-relayer/logging"
+	"github.com/zkMeLabs/mechain-relayer/signer"
+	rtypes "github.com/zkMeLabs/mechain-relayer/types"
+)
+
+// RelayerKeys are the public identities derived from the configured relayer keys.
+type RelayerKeys struct {
+	GreenfieldAddress string
+	BSCAddress        common.Address
+	BlsPubKey         []byte
+}
+
+// Equal reports whether both identities are the same.
+func (k *RelayerKeys) Equal(other *RelayerKeys) bool {
+	return k.GreenfieldAddress == other.GreenfieldAddress && k.BSCAddress == other.BSCAddress &&
+		bytes.Equal(k.BlsPubKey, other.BlsPubKey)
+}
+
+// IsGreenfieldRelayer reports whether relayerAddress, as registered with a Mechain validator, is the Mechain account.
+func (k *RelayerKeys) IsGreenfieldRelayer(relayerAddress []byte) bool {
+	return sdk.AccAddress(relayerAddress).String() == k.GreenfieldAddress
+}
+
+// RelayerKeySet holds the relayer keys loaded from the key sources of a config.
+type RelayerKeySet struct {
+	GreenfieldPrivateKey string
+	BlsPrivateKey        []byte
+	BSCSigner            signer.Signer
+}
+
+// LoadRelayerKeySet loads the relayer keys the same way as the executors do, without connecting to any chain.
+func LoadRelayerKeySet(cfg *config.Config) (*RelayerKeySet, error) {
+	privKey, err := getGreenfieldPrivateKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	blsPrivKey, err := getGreenfieldBlsPrivateKeyBytes(cfg)
+	if err != nil {
+		return nil, err
+	}
+	bscSigner, err := getBscSigner(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bsc key, err=%s", err.Error())
+	}
+	return &RelayerKeySet{
+		GreenfieldPrivateKey: privKey,
+		BlsPrivateKey:        blsPrivKey,
+		BSCSigner:            bscSigner,
+	}, nil
+}
+
+// RelayerKeys returns the public identities of the key set.
+func (s *RelayerKeySet) RelayerKeys() (*RelayerKeys, error) {
+	greenfieldAddress, err := getGreenfieldAddress(s.GreenfieldPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	blsPubKey, err := blsPublicKey(hex.EncodeToString(s.BlsPrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	return &RelayerKeys{
+		GreenfieldAddress: greenfieldAddress,
+		BSCAddress:        s.BSCSigner.Address(),
+		BlsPubKey:         blsPubKey,
+	}, nil
+}
+
+// GetRelayerKeys loads the relayer keys the same way as the executors do, without connecting to any chain.
+func GetRelayerKeys(cfg *config.Config) (*RelayerKeys, error) {
+	keySet, err := LoadRelayerKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return keySet.RelayerKeys()
+}
+
+// DeriveRelayerKeys derives the public identities from hex encoded private keys. The Mechain private key is also used
+// as the BSC private key if bscPrivKey is empty.
+func DeriveRelayerKeys(privKey, bscPrivKey, blsPrivKey string) (*RelayerKeys, error) {
+	greenfieldAddress, err := getGreenfieldAddress(privKey)
+	if err != nil {
+		return nil, err
+	}
+	if bscPrivKey == "" {
+		bscPrivKey = privKey
+	}
+	bscKey, err := crypto.HexToECDSA(strings.TrimPrefix(bscPrivKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bsc private key, err=%s", err.Error())
+	}
+	blsPubKey, err := blsPublicKey(blsPrivKey)
+	if err != nil {
+		return nil, err
+	}
+	return &RelayerKeys{
+		GreenfieldAddress: greenfieldAddress,
+		BSCAddress:        crypto.PubkeyToAddress(bscKey.PublicKey),
+		BlsPubKey:         blsPubKey,
+	}, nil
+}
+
+// FindValidatorByBlsKey returns the Mechain validator whose BLS public key is blsPubKey, or nil if there is none. It
+// only queries the configured RPC nodes, so it works without loading the relayer keys.
+func FindValidatorByBlsKey(cfg *config.Config, blsPubKey []byte) (*tmtypes.Validator, error) {
+	if len(cfg.GreenfieldConfig.RPCAddrs) == 0 {
+		return nil, fmt.Errorf("no Mechain rpc address is configured")
+	}
+	client, err := rpchttp.New(cfg.GreenfieldConfig.RPCAddrs[0], "/websocket")
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial Mechain rpc, err=%s", err.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+	defer cancel()
+	perPage := 100
+	for page := 1; ; page++ {
+		res, err := client.Validators(ctx, nil, &page, &perPage)
+		if err != nil {
+			return nil, err
+		}
+		for _, validator := range res.Validators {
+			if bytes.Equal(validator.BlsKey, blsPubKey) {
+				return validator, nil
+			}
+		}
+		if len(res.Validators) == 0 || page*perPage >= res.Total {
+			return nil, nil
+		}
+	}
+}
+
+// FindRelayerByBlsKey returns the relayer of the light client contract whose BLS public key is blsPubKey, or nil if
+// there is none. It only queries the configured RPC nodes, so it works without loading the relayer keys.
+func FindRelayerByBlsKey(cfg *config.Config, blsPubKey []byte) (*rtypes.Validator, error) {
+	if len(cfg.BSCConfig.RPCAddrs) == 0 {
+		return nil, fmt.Errorf("no BSC rpc address is configured")
+	}
+	ethClient, err := ethclient.Dial(cfg.BSCConfig.RPCAddrs[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial BSC rpc, err=%s", err.Error())
+	}
+	defer ethClient.Close()
+	lightClient, err := greenfieldlightclient.NewGreenfieldlightclient(
+		common.HexToAddress(cfg.RelayConfig.GreenfieldLightClientContractAddr), ethClient)
+	if err != nil {
+		return nil, err
+	}
+	relayers, err := queryLightClientRelayers(lightClient)
+	if err != nil {
+		return nil, err
+	}
+	for i := range relayers {
+		if bytes.Equal(relayers[i].BlsPublicKey, blsPubKey) {
+			return &relayers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func getGreenfieldAddress(privKey string) (string, error) {
+	account, err := sdktypes.NewAccountFromPrivateKey("relayer", strings.TrimPrefix(privKey, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("failed to load greenfield private key, err=%s", err.Error())
+	}
+	return account.GetAddress().String(), nil
+}
+
+// blsPublicKey returns the BLS public key the vote signer signs with.
+func blsPublicKey(blsPrivKey string) ([]byte, error) {
+	blsPrivKeyBts, err := hex.DecodeString(strings.TrimPrefix(blsPrivKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bls private key, err=%s", err.Error())
+	}
+	privKey, err := bls.UnmarshalPrivateKey(blsPrivKeyBts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bls private key, err=%s", err.Error())
+	}
+	return privKey.PublicKey().Marshal(), nil
+}
+
+func (e *GreenfieldExecutor) getKeys() *greenfieldKeys {
+	e.keyMutex.RLock()
+	defer e.keyMutex.RUnlock()
+	return e.keys
+}
+
+func (e *GreenfieldExecutor) GetAddress() string {
+	return e.getKeys().account.GetAddress().String()
+}
+
+// GetBlsPrivateKey returns the BLS private key of the relayer.
+func (e *GreenfieldExecutor) GetBlsPrivateKey() []byte {
+	return e.getKeys().blsPrivateKey
+}
+
+// GetBlsPubKey returns the BLS public key of the relayer.
+func (e *GreenfieldExecutor) GetBlsPubKey() []byte {
+	return e.getKeys().blsPubKey
+}
+
+// SetKeys swaps the Mechain account, the EVM signer and the BLS key. It must be called while the relay is paused.
+func (e *GreenfieldExecutor) SetKeys(privKey string, blsPrivKey []byte) error {
+	keys, err := newGreenfieldKeys(privKey, blsPrivKey)
+	if err != nil {
+		return err
+	}
+	e.keyMutex.Lock()
+	defer e.keyMutex.Unlock()
+	e.gnfdClients.SetDefaultAccount(keys.account)
+	e.keys = keys
+	return nil
+}
+
+// BeginRelay is called before a round which signs with the relayer keys and sends the transactions or votes, and
+// EndRelay after it, so the keys are never swapped in the middle of a round. Every signing path of the relayer, on both
+// chains, shares this gate.
+func (e *GreenfieldExecutor) BeginRelay() {
+	e.relayGate.Enter()
+}
+
+func (e *GreenfieldExecutor) EndRelay() {
+	e.relayGate.Leave()
+}
+
+// PauseRelay waits for the running rounds to finish and blocks new ones until ResumeRelay is called.
+func (e *GreenfieldExecutor) PauseRelay() {
+	e.relayGate.Pause()
+}
+
+func (e *GreenfieldExecutor) ResumeRelay() {
+	e.relayGate.Resume()
+}
+
+// BeginRelay enters the relay gate of the Mechain executor, see GreenfieldExecutor.BeginRelay.
+func (e *BSCExecutor) BeginRelay() {
+	e.GreenfieldExecutor.BeginRelay()
+}
+
+func (e *BSCExecutor) EndRelay() {
+	e.GreenfieldExecutor.EndRelay()
+}
+
+// WaitForPendingTxs waits until the transactions sent by the relayer account to the Greenfield EVM are included, and
+// for one more block, in which the claims waiting in the mempool are included.
+func (e *GreenfieldExecutor) WaitForPendingTxs(ctx context.Context) error {
+	if err := waitForPendingTxs(ctx, e.GetEthClient(), e.getKeys().evmSigner.Address()); err != nil {
+		return err
+	}
+	return e.GetGnfdClient().WaitForNextBlock(ctx)
+}
+
+func (e *BSCExecutor) getSigner() signer.Signer {
+	e.keyMutex.RLock()
+	defer e.keyMutex.RUnlock()
+	return e.signer
+}
+
+func (e *BSCExecutor) GetAddress() common.Address {
+	return e.getSigner().Address()
+}
+
+// SetSigner swaps the signer of the BSC transactions and resyncs the nonce of the new account. It must be called while
+// the relay is paused.
+func (e *BSCExecutor) SetSigner(s signer.Signer) {
+	e.keyMutex.Lock()
+	e.signer = s
+	e.keyMutex.Unlock()
+	e.nonceManager.Resync()
+}
+
+// WaitForPendingTxs waits until the transactions sent by the relayer account are included.
+func (e *BSCExecutor) WaitForPendingTxs(ctx context.Context) error {
+	return waitForPendingTxs(ctx, e.GetEthClient(), e.GetAddress())
+}
+
+func waitForPendingTxs(ctx context.Context, client *ethclient.Client, account common.Address) error {
+	for {
+		pending, err := client.PendingNonceAt(ctx, account)
+		if err != nil {
+			return err
+		}
+		latest, err := client.NonceAt(ctx, account, nil)
+		if err != nil {
+			return err
+		}
+		if pending <= latest {
+			return nil
+		}
+		logging.Logger.Infof("waiting for %d pending transactions of %s", pending-latest, account.Hex())
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d transactions of %s are still pending, err=%s", pending-latest, account.Hex(), ctx.Err().Error())
+		case <-time.After(PendingTxsPollInterval):
+		}
+	}
+}
+
+// getKeystorePrivateKey decrypts the keystore file with the password from passwordFile or the KEYSTORE_PASSWORD
+// environment variable, and returns the hex encoded private key.
+func getKeystorePrivateKey(decrypt func(keystoreFile, password string) ([]byte, error), keystoreFile, passwordFile string) (string, error) {
+	password, err := keystore.LoadPassword(passwordFile)
+	if err != nil {
+		return "", err
+	}
+	privKey, err := decrypt(keystoreFile, password)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(privKey), nil
+}
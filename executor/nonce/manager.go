@@ -0,0 +1,119 @@
+package nonce
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FetchFunc returns the pending nonce of the account from chain.
+type FetchFunc func() (uint64, error)
+
+// Manager allocates the nonces of one sending account, so the transactions of concurrent write paths never share a
+// nonce. A nonce is reserved before a transaction is signed, then either committed once the node accepted the
+// transaction, or released if it was not sent so the next reservation reuses it. The next nonce is read from chain on
+// the first reservation and after Resync, nonces reserved but not yet committed or released are never handed out twice.
+type Manager struct {
+	mutex    sync.Mutex
+	fetch    FetchFunc
+	synced   bool
+	next     uint64
+	released []uint64            // released nonces below next, reused lowest first
+	inFlight map[uint64]struct{} // reserved nonces which are neither committed nor released
+}
+
+func NewManager(fetch FetchFunc) *Manager {
+	return &Manager{
+		fetch:    fetch,
+		inFlight: make(map[uint64]struct{}),
+	}
+}
+
+// Reserve returns the nonce of the next transaction.
+func (m *Manager) Reserve() (uint64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if !m.synced {
+		pending, err := m.fetch()
+		if err != nil {
+			return 0, err
+		}
+		m.next = pending
+		for n := range m.inFlight {
+			if n >= m.next {
+				m.next = n + 1
+			}
+		}
+		m.released = m.released[:0]
+		m.synced = true
+	}
+	var n uint64
+	if len(m.released) > 0 {
+		n = m.released[0]
+		m.released = m.released[1:]
+	} else {
+		n = m.next
+		m.next++
+	}
+	m.inFlight[n] = struct{}{}
+	return n, nil
+}
+
+// Commit marks the nonce as used by a transaction accepted by the node.
+func (m *Manager) Commit(n uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.inFlight, n)
+}
+
+// Release gives back a reserved nonce whose transaction was not sent.
+func (m *Manager) Release(n uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, ok := m.inFlight[n]; !ok {
+		return
+	}
+	delete(m.inFlight, n)
+	if !m.synced || n >= m.next {
+		return
+	}
+	if n+1 == m.next {
+		m.next = n
+		// the released nonces just below are now at the top as well
+		for len(m.released) > 0 && m.released[len(m.released)-1]+1 == m.next {
+			m.next--
+			m.released = m.released[:len(m.released)-1]
+		}
+		return
+	}
+	m.released = append(m.released, n)
+	sort.Slice(m.released, func(i, j int) bool { return m.released[i] < m.released[j] })
+}
+
+// Resync makes the next reservation read the pending nonce from chain, it is called when a transaction failed because of
+// its nonce, or when the sending account changed.
+func (m *Manager) Resync() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.synced = false
+}
+
+// IsNonceError reports whether the transaction was rejected by the node because of its nonce.
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"nonce too low", "nonce too high", "replacement transaction underpriced", "invalid nonce"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAlreadyKnown reports whether the node rejected the transaction because it already has it in its pool. The
+// transaction is sent then, and its nonce is used.
+func IsAlreadyKnown(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "already known")
+}
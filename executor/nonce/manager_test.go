@@ -0,0 +1,102 @@
+package nonce
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagerReserveAndRelease(t *testing.T) {
+	pending := uint64(10)
+	fetched := 0
+	m := NewManager(func() (uint64, error) {
+		fetched++
+		return pending, nil
+	})
+
+	for i := uint64(0); i < 3; i++ {
+		n, err := m.Reserve()
+		require.NoError(t, err)
+		require.Equal(t, 10+i, n)
+	}
+	require.Equal(t, 1, fetched)
+
+	// a gap is filled by the next reservation
+	m.Commit(10)
+	m.Release(11)
+	m.Commit(12)
+	n, err := m.Reserve()
+	require.NoError(t, err)
+	require.Equal(t, uint64(11), n)
+	n, err = m.Reserve()
+	require.NoError(t, err)
+	require.Equal(t, uint64(13), n)
+
+	// releasing the top nonces rolls the counter back
+	m.Commit(11)
+	m.Release(13)
+	n, err = m.Reserve()
+	require.NoError(t, err)
+	require.Equal(t, uint64(13), n)
+
+	// the in flight nonce is kept after a resync behind the chain
+	m.Resync()
+	pending = 12
+	n, err = m.Reserve()
+	require.NoError(t, err)
+	require.Equal(t, uint64(14), n)
+	require.Equal(t, 2, fetched)
+
+	m.Release(14)
+	m.Release(13)
+	n, err = m.Reserve()
+	require.NoError(t, err)
+	require.Equal(t, uint64(13), n)
+}
+
+func TestManagerFetchError(t *testing.T) {
+	m := NewManager(func() (uint64, error) {
+		return 0, errors.New("rpc error")
+	})
+	_, err := m.Reserve()
+	require.Error(t, err)
+}
+
+func TestManagerConcurrentReserve(t *testing.T) {
+	m := NewManager(func() (uint64, error) {
+		return 0, nil
+	})
+	var (
+		wg     sync.WaitGroup
+		mutex  sync.Mutex
+		nonces = make(map[uint64]struct{})
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := m.Reserve()
+			require.NoError(t, err)
+			mutex.Lock()
+			nonces[n] = struct{}{}
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+	require.Len(t, nonces, 50)
+}
+
+func TestIsNonceError(t *testing.T) {
+	require.True(t, IsNonceError(errors.New("nonce too low")))
+	require.False(t, IsNonceError(errors.New("Already known")))
+	require.False(t, IsNonceError(errors.New("insufficient funds for gas * price + value")))
+	require.False(t, IsNonceError(nil))
+}
+
+func TestIsAlreadyKnown(t *testing.T) {
+	require.True(t, IsAlreadyKnown(errors.New("Already known")))
+	require.False(t, IsAlreadyKnown(errors.New("nonce too low")))
+	require.False(t, IsAlreadyKnown(nil))
+}
@@ -11,7 +11,11 @@ import (
 )
 
 func GetTestConfig() *config.Config {
-	return config.ParseConfigFromFile("../integrationtest/config/config_test.json")
+	cfg, err := config.ParseConfigFromFile("../integrationtest/config/config_test.json")
+	if err != nil {
+		panic(err)
+	}
+	return cfg
 }
 
 func InitExecutors() (*BSCExecutor, *GreenfieldExecutor) {
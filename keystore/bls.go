@@ -0,0 +1,246 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The EIP-2335 keystore format is used for BLS keys, see https://eips.ethereum.org/EIPS/eip-2335
+const (
+	BLSKeystoreVersion = 4
+
+	kdfScrypt       = "scrypt"
+	kdfPBKDF2       = "pbkdf2"
+	checksumSHA256  = "sha256"
+	cipherAES128CTR = "aes-128-ctr"
+	prfHmacSHA256   = "hmac-sha256"
+	derivedKeyLen   = 32
+
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	LightScryptN    = 1 << 12
+	LightScryptP    = 6
+	scryptR         = 8
+)
+
+type blsKeystoreModule struct {
+	Function string                 `json:"function"`
+	Params   map[string]interface{} `json:"params"`
+	Message  string                 `json:"message"`
+}
+
+type blsKeystoreCrypto struct {
+	KDF      blsKeystoreModule `json:"kdf"`
+	Checksum blsKeystoreModule `json:"checksum"`
+	Cipher   blsKeystoreModule `json:"cipher"`
+}
+
+// BLSKeystore is an EIP-2335 keystore.
+type BLSKeystore struct {
+	Crypto      blsKeystoreCrypto `json:"crypto"`
+	Description string            `json:"description"`
+	PubKey      string            `json:"pubkey"`
+	Path        string            `json:"path"`
+	UUID        string            `json:"uuid"`
+	Version     int               `json:"version"`
+}
+
+// DecryptBLSKeyFile decrypts the EIP-2335 keystore file with password and returns the raw BLS private key.
+func DecryptBLSKeyFile(keystoreFile, password string) ([]byte, error) {
+	keyJSON, err := os.ReadFile(keystoreFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bls keystore file %s, err=%s", keystoreFile, err.Error())
+	}
+	secret, err := DecryptBLSKey(keyJSON, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt bls keystore file %s, err=%s", keystoreFile, err.Error())
+	}
+	return secret, nil
+}
+
+// DecryptBLSKey decrypts an EIP-2335 keystore with password.
+func DecryptBLSKey(keyJSON []byte, password string) ([]byte, error) {
+	var ks BLSKeystore
+	if err := json.Unmarshal(keyJSON, &ks); err != nil {
+		return nil, fmt.Errorf("invalid keystore json, err=%s", err.Error())
+	}
+	if ks.Version != BLSKeystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version %d", ks.Version)
+	}
+	if ks.Crypto.Checksum.Function != checksumSHA256 {
+		return nil, fmt.Errorf("unsupported checksum function %s", ks.Crypto.Checksum.Function)
+	}
+	if ks.Crypto.Cipher.Function != cipherAES128CTR {
+		return nil, fmt.Errorf("unsupported cipher function %s", ks.Crypto.Cipher.Function)
+	}
+
+	derivedKey, err := deriveKey(&ks.Crypto.KDF, processPassword(password))
+	if err != nil {
+		return nil, err
+	}
+	cipherMessage, err := hex.DecodeString(ks.Crypto.Cipher.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cipher message, err=%s", err.Error())
+	}
+	checksum, err := hex.DecodeString(ks.Crypto.Checksum.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum message, err=%s", err.Error())
+	}
+	if !bytes.Equal(blsKeystoreChecksum(derivedKey, cipherMessage), checksum) {
+		return nil, fmt.Errorf("wrong password or corrupted keystore, checksum mismatch")
+	}
+	iv, err := hexParam(ks.Crypto.Cipher.Params, "iv")
+	if err != nil {
+		return nil, err
+	}
+	return aes128CTR(derivedKey[:16], iv, cipherMessage)
+}
+
+// EncryptBLSKey encrypts the BLS private key secret with password into an EIP-2335 keystore, using scrypt with
+// scryptN and scryptP as the key derivation function.
+func EncryptBLSKey(secret, pubKey []byte, password string, scryptN, scryptP int) ([]byte, error) {
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	kdf := blsKeystoreModule{
+		Function: kdfScrypt,
+		Params: map[string]interface{}{
+			"dklen": derivedKeyLen,
+			"n":     scryptN,
+			"p":     scryptP,
+			"r":     scryptR,
+			"salt":  hex.EncodeToString(salt),
+		},
+	}
+	derivedKey, err := scrypt.Key(processPassword(password), salt, scryptN, scryptR, scryptP, derivedKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	cipherMessage, err := aes128CTR(derivedKey[:16], iv, secret)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(&BLSKeystore{
+		Crypto: blsKeystoreCrypto{
+			KDF: kdf,
+			Checksum: blsKeystoreModule{
+				Function: checksumSHA256,
+				Params:   map[string]interface{}{},
+				Message:  hex.EncodeToString(blsKeystoreChecksum(derivedKey, cipherMessage)),
+			},
+			Cipher: blsKeystoreModule{
+				Function: cipherAES128CTR,
+				Params:   map[string]interface{}{"iv": hex.EncodeToString(iv)},
+				Message:  hex.EncodeToString(cipherMessage),
+			},
+		},
+		PubKey:  hex.EncodeToString(pubKey),
+		UUID:    uuid.New().String(),
+		Version: BLSKeystoreVersion,
+	}, "", "  ")
+}
+
+func deriveKey(kdf *blsKeystoreModule, password []byte) ([]byte, error) {
+	salt, err := hexParam(kdf.Params, "salt")
+	if err != nil {
+		return nil, err
+	}
+	dkLen, err := intParam(kdf.Params, "dklen")
+	if err != nil {
+		return nil, err
+	}
+	if dkLen != derivedKeyLen {
+		return nil, fmt.Errorf("unsupported dklen %d", dkLen)
+	}
+	switch kdf.Function {
+	case kdfScrypt:
+		n, err := intParam(kdf.Params, "n")
+		if err != nil {
+			return nil, err
+		}
+		r, err := intParam(kdf.Params, "r")
+		if err != nil {
+			return nil, err
+		}
+		p, err := intParam(kdf.Params, "p")
+		if err != nil {
+			return nil, err
+		}
+		return scrypt.Key(password, salt, n, r, p, dkLen)
+	case kdfPBKDF2:
+		if prf, _ := kdf.Params["prf"].(string); prf != prfHmacSHA256 {
+			return nil, fmt.Errorf("unsupported pbkdf2 prf %v", kdf.Params["prf"])
+		}
+		c, err := intParam(kdf.Params, "c")
+		if err != nil {
+			return nil, err
+		}
+		return pbkdf2.Key(password, salt, c, dkLen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("unsupported kdf function %s", kdf.Function)
+	}
+}
+
+// processPassword normalizes the password to NFKD and strips the control codes as EIP-2335 requires.
+func processPassword(password string) []byte {
+	return []byte(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, norm.NFKD.String(password)))
+}
+
+func blsKeystoreChecksum(derivedKey, cipherMessage []byte) []byte {
+	h := sha256.Sum256(append(append([]byte{}, derivedKey[16:32]...), cipherMessage...))
+	return h[:]
+}
+
+func aes128CTR(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+func hexParam(params map[string]interface{}, name string) ([]byte, error) {
+	s, ok := params[name].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing keystore param %s", name)
+	}
+	bz, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore param %s, err=%s", name, err.Error())
+	}
+	return bz, nil
+}
+
+func intParam(params map[string]interface{}, name string) (int, error) {
+	f, ok := params[name].(float64)
+	if !ok {
+		return 0, fmt.Errorf("missing keystore param %s", name)
+	}
+	return int(f), nil
+}
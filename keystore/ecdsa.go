@@ -0,0 +1,37 @@
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// DecryptECDSAKeyFile decrypts the go-ethereum keystore file with password and returns the raw private key.
+func DecryptECDSAKeyFile(keystoreFile, password string) ([]byte, error) {
+	keyJSON, err := os.ReadFile(keystoreFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file %s, err=%s", keystoreFile, err.Error())
+	}
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore file %s, err=%s", keystoreFile, err.Error())
+	}
+	return crypto.FromECDSA(key.PrivateKey), nil
+}
+
+// EncryptECDSAKey encrypts privKey with password into a go-ethereum keystore file.
+func EncryptECDSAKey(privKey *ecdsa.PrivateKey, password string, scryptN, scryptP int) ([]byte, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	return keystore.EncryptKey(&keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(privKey.PublicKey),
+		PrivateKey: privKey,
+	}, password, scryptN, scryptP)
+}
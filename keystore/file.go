@@ -0,0 +1,80 @@
+package keystore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// WriteKeyFiles writes the files, keyed by name, into dir. Existing files are never overwritten, and either all of the
+// files are written or none of them: the contents are written to temporary files first, which are renamed at the end.
+func WriteKeyFiles(dir string, files map[string][]byte) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if _, err := os.Lstat(path); err == nil {
+			return fmt.Errorf("key file %s already exists", path)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	tmpPaths := make(map[string]string, len(names))
+	defer func() {
+		for _, tmpPath := range tmpPaths {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+	for _, name := range names {
+		tmpPath, err := writeTempFile(dir, name, files[name])
+		if err != nil {
+			return err
+		}
+		tmpPaths[name] = tmpPath
+	}
+
+	renamed := make([]string, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.Rename(tmpPaths[name], path); err != nil {
+			for _, p := range renamed {
+				_ = os.Remove(p)
+			}
+			return fmt.Errorf("failed to write key file %s, err=%s", path, err.Error())
+		}
+		delete(tmpPaths, name)
+		renamed = append(renamed, path)
+	}
+	return nil
+}
+
+// writeTempFile writes the content to a new temporary file in dir, readable by the owner only.
+func writeTempFile(dir, name string, content []byte) (string, error) {
+	f, err := os.CreateTemp(dir, "."+name+".tmp*")
+	if err != nil {
+		return "", err
+	}
+	if _, err = f.Write(content); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
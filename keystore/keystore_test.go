@@ -0,0 +1,112 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// test vectors from EIP-2335
+const (
+	eip2335Password = "\U0001d531\U0001d522\U0001d530\U0001d531\U0001d52d\U0001d51e\U0001d530\U0001d530\U0001d534\U0001d52c\U0001d52f\U0001d521\U0001f511"
+	eip2335Secret   = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+
+	eip2335ScryptKeystore = `{
+  "crypto": {
+    "kdf": {"function": "scrypt", "params": {"dklen": 32, "n": 262144, "p": 1, "r": 8, "salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"}, "message": ""},
+    "checksum": {"function": "sha256", "params": {}, "message": "d2217fe5f3e9a1e34581ef8a78f7c9928e436d36dacc5e846690a5581e8ea484"},
+    "cipher": {"function": "aes-128-ctr", "params": {"iv": "264daa3f303d7259501c93d997d84fe6"}, "message": "06ae90d55fe0a6e9c5c3bc5b170827b2e5cce3929ed3f116c2811e6366dfe20f"}
+  },
+  "pubkey": "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+  "path": "m/12381/60/3141592653/589793238",
+  "uuid": "1d85ae20-35c5-4611-98e8-aa14a633906f",
+  "version": 4
+}`
+
+	eip2335PBKDF2Keystore = `{
+  "crypto": {
+    "kdf": {"function": "pbkdf2", "params": {"dklen": 32, "c": 262144, "prf": "hmac-sha256", "salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"}, "message": ""},
+    "checksum": {"function": "sha256", "params": {}, "message": "8a9f5d9912ed7e75ea794bc5a89bca5f193721d30868ade6f73043c6ea6febf1"},
+    "cipher": {"function": "aes-128-ctr", "params": {"iv": "264daa3f303d7259501c93d997d84fe6"}, "message": "cee03fde2af33149775b7223e7845e4fb2c8ae1792e5f99fe9ecf474cc8c16ad"}
+  },
+  "pubkey": "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+  "path": "m/12381/60/0/0",
+  "uuid": "64625def-3331-4eea-ab6f-782f3ed16a83",
+  "version": 4
+}`
+)
+
+func TestDecryptBLSKeyTestVectors(t *testing.T) {
+	for _, keyJSON := range []string{eip2335ScryptKeystore, eip2335PBKDF2Keystore} {
+		secret, err := DecryptBLSKey([]byte(keyJSON), eip2335Password)
+		require.NoError(t, err)
+		require.Equal(t, eip2335Secret, hex.EncodeToString(secret))
+
+		_, err = DecryptBLSKey([]byte(keyJSON), "wrong")
+		require.Error(t, err)
+	}
+}
+
+func TestEncryptBLSKey(t *testing.T) {
+	secret, err := hex.DecodeString(eip2335Secret)
+	require.NoError(t, err)
+	keyJSON, err := EncryptBLSKey(secret, []byte{1}, "password", LightScryptN, LightScryptP)
+	require.NoError(t, err)
+
+	keystoreFile := filepath.Join(t.TempDir(), "bls_keystore.json")
+	require.NoError(t, os.WriteFile(keystoreFile, keyJSON, 0o600))
+	decrypted, err := DecryptBLSKeyFile(keystoreFile, "password")
+	require.NoError(t, err)
+	require.Equal(t, secret, decrypted)
+}
+
+func TestEncryptECDSAKey(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keyJSON, err := EncryptECDSAKey(privKey, "password", LightScryptN, LightScryptP)
+	require.NoError(t, err)
+
+	keystoreFile := filepath.Join(t.TempDir(), "keystore.json")
+	require.NoError(t, os.WriteFile(keystoreFile, keyJSON, 0o600))
+	decrypted, err := DecryptECDSAKeyFile(keystoreFile, "password")
+	require.NoError(t, err)
+	require.Equal(t, crypto.FromECDSA(privKey), decrypted)
+}
+
+func TestLoadPassword(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret\n"), 0o600))
+	password, err := LoadPassword(passwordFile)
+	require.NoError(t, err)
+	require.Equal(t, "secret", password)
+
+	t.Setenv(PasswordEnv, "from-env")
+	password, err = LoadPassword("")
+	require.NoError(t, err)
+	require.Equal(t, "from-env", password)
+}
+
+func TestWriteKeyFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+	files := map[string][]byte{"a.json": []byte("a"), "b.json": []byte("b")}
+	require.NoError(t, WriteKeyFiles(dir, files))
+	for name, content := range files {
+		bz, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.Equal(t, content, bz)
+	}
+
+	// an existing key is never overwritten and no other file is written
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.json")))
+	require.Error(t, WriteKeyFiles(dir, map[string][]byte{"a.json": []byte("new"), "b.json": []byte("new")}))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	bz, err := os.ReadFile(filepath.Join(dir, "b.json"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("b"), bz)
+}
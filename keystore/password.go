@@ -0,0 +1,29 @@
+package keystore
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+)
+
+// PasswordEnv is the environment variable read for the keystore password when no password file is configured.
+const PasswordEnv = config.KeystorePasswordEnv
+
+// LoadPassword reads the keystore password from passwordFile, or from the KEYSTORE_PASSWORD environment variable if
+// passwordFile is empty. The trailing line break of the file is not part of the password.
+func LoadPassword(passwordFile string) (string, error) {
+	if passwordFile != "" {
+		bz, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file %s, err=%s", passwordFile, err.Error())
+		}
+		return strings.TrimRight(string(bz), "\r\n"), nil
+	}
+	password, ok := os.LookupEnv(PasswordEnv)
+	if !ok {
+		return "", fmt.Errorf("neither a keystore password file nor the %s environment variable is set", PasswordEnv)
+	}
+	return password, nil
+}
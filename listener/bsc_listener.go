@@ -32,6 +32,8 @@ type BSCListener struct {
 	DaoManager         *dao.DaoManager
 	crossChainAbi      abi.ABI
 	monitorService     *metric.MetricService
+	logRangeSize       uint64        // number of blocks scanned by the next range log query in catch-up mode
+	newBlockCh         chan struct{} // signaled by the websocket subscription when a new head or cross chain log arrives
 }
 
 func NewBSCListener(cfg *config.Config, bscExecutor *executor.BSCExecutor, gnfdExecutor *executor.GreenfieldExecutor, dao *dao.DaoManager, ms *metric.MetricService) *BSCListener {
@@ -46,10 +48,15 @@ func NewBSCListener(cfg *config.Config, bscExecutor *executor.BSCExecutor, gnfdE
 		DaoManager:         dao,
 		crossChainAbi:      crossChainAbi,
 		monitorService:     ms,
+		logRangeSize:       cfg.BSCConfig.GetCatchUpBatchSize(),
+		newBlockCh:         make(chan struct{}, 1),
 	}
 }
 
 func (l *BSCListener) StartLoop() {
+	if l.config.BSCConfig.WebsocketAddr != "" {
+		go l.SubscribeLoop()
+	}
 	for {
 		if err := l.poll(); err != nil {
 			logging.Logger.Errorf("encounter err, err=%s", err.Error())
@@ -82,9 +89,14 @@ func (l *BSCListener) poll() error {
 			return err
 		}
 		if int64(latestPolledBlockHeight) >= int64(latestBlockHeight)-1 {
-			time.Sleep(common.ListenerPauseTime)
+			l.waitForNewBlock()
 			return nil
 		}
+		// far behind the head, scan block ranges until the last blocks, which are polled one by one to detect reorgs
+		threshold := l.config.BSCConfig.GetCatchUpThreshold()
+		if latestBlockHeight >= nextHeight+threshold {
+			return l.catchUp(nextHeight, latestBlockHeight-threshold, latestPolledBlock)
+		}
 	}
 	if err = l.monitorCrossChainPkgAt(nextHeight, latestPolledBlock); err != nil {
 		return err
@@ -107,7 +119,12 @@ func (l *BSCListener) monitorCrossChainPkgAt(nextHeight uint64, latestPolledBloc
 	}
 	logging.Logger.Infof("retrieved BSC block header at height=%d", nextHeight)
 
-	logs, err := l.queryCrossChainLogs(nextHeight)
+	if (*latestPolledBlock != model.BscBlock{}) && latestPolledBlock.Height+1 == nextHeight &&
+		nextHeightBlockHeader.ParentHash.String() != latestPolledBlock.BlockHash {
+		return l.rollbackReorg(latestPolledBlock)
+	}
+
+	logs, err := l.queryCrossChainLogs(nextHeight, nextHeight)
 	if err != nil {
 		return fmt.Errorf("failed to get logs from block at height=%d, err=%s", nextHeight, err.Error())
 	}
@@ -129,25 +146,194 @@ func (l *BSCListener) monitorCrossChainPkgAt(nextHeight uint64, latestPolledBloc
 		relayPkgs = append(relayPkgs, relayPkg)
 	}
 
-	if err = l.DaoManager.BSCDao.SaveBlockAndBatchPackages(
-		&model.BscBlock{
-			BlockHash:  nextHeightBlockHeader.Hash().String(),
-			ParentHash: nextHeightBlockHeader.ParentHash.String(),
-			Height:     nextHeight,
-			BlockTime:  int64(nextHeightBlockHeader.Time),
-		}, relayPkgs); err != nil {
+	if err = l.DaoManager.BSCDao.SaveBlockAndBatchPackages(newBscBlock(nextHeightBlockHeader), relayPkgs); err != nil {
 		return fmt.Errorf("failed to persist block and tx to DB, err=%s", err.Error())
 	}
 	l.monitorService.SetBSCSavedBlockHeight(nextHeight)
 	return nil
 }
 
-func (l *BSCListener) queryCrossChainLogs(height uint64) ([]types.Log, error) {
+// rollbackReorg is called when the parent hash of the next block does not match the latest polled block. It walks back
+// over the stored blocks until one matches the chain and deletes the blocks, packages and votes above that common
+// ancestor, so the orphaned blocks are ingested again from the canonical chain by the next polls.
+func (l *BSCListener) rollbackReorg(latestPolledBlock *model.BscBlock) error {
+	ancestorHeight, err := findCommonAncestor(latestPolledBlock, l.DaoManager.BSCDao.GetLatestBlockBelowHeight, l.getCanonicalBlockHash)
+	if err != nil {
+		return err
+	}
+	depth := latestPolledBlock.Height - ancestorHeight
+	if depth == 0 {
+		// the latest polled block is still canonical, the next block header was fetched from a stale node
+		return nil
+	}
+	logging.Logger.Infof("BSC reorg detected at height %d, rolling back %d blocks to height %d", latestPolledBlock.Height+1, depth, ancestorHeight)
+	if err := l.DaoManager.BSCDao.DeleteBlocksAndPackagesAboveHeight(ancestorHeight); err != nil {
+		return fmt.Errorf("failed to roll back blocks above height %d, err=%s", ancestorHeight, err.Error())
+	}
+	l.monitorService.RecordBSCReorg(depth)
+	l.monitorService.SetBSCSavedBlockHeight(ancestorHeight)
+	return nil
+}
+
+func (l *BSCListener) getCanonicalBlockHash(height uint64) (string, bool, error) {
+	header, err := l.bscExecutor.GetBlockHeaderAtHeight(height)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get block header at height %d, err=%s", height, err.Error())
+	}
+	if header == nil {
+		return "", false, nil
+	}
+	return header.Hash().String(), true, nil
+}
+
+// findCommonAncestor returns the height of the latest stored block which is still on the canonical chain. The stored
+// blocks are compared from the latest one down, skipping the heights which are not stored, at most MaxBSCReorgDepth
+// of them. If none of the older stored blocks matches, all of them are orphaned and the height below the oldest is
+// returned.
+func findCommonAncestor(latest *model.BscBlock, getBlockBelow func(height uint64) (*model.BscBlock, error),
+	getCanonicalHash func(height uint64) (string, bool, error),
+) (uint64, error) {
+	block := latest
+	for compared := 0; ; compared++ {
+		if compared >= MaxBSCReorgDepth {
+			return 0, fmt.Errorf("reorg at height %d is deeper than %d stored blocks, rewind the DB manually", latest.Height, MaxBSCReorgDepth)
+		}
+		hash, ok, err := getCanonicalHash(block.Height)
+		if err != nil {
+			return 0, err
+		}
+		if ok && hash == block.BlockHash {
+			return block.Height, nil
+		}
+		older, err := getBlockBelow(block.Height)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get block below height %d from DB, err=%s", block.Height, err.Error())
+		}
+		if (*older == model.BscBlock{}) {
+			if block.Height == 0 {
+				return 0, nil
+			}
+			return block.Height - 1, nil
+		}
+		block = older
+	}
+}
+
+// catchUp scans the cross chain logs from the height up to at most maxHeight with one range query, and saves the blocks
+// containing packages together with the last scanned block. The range shrinks while the RPC node rejects the query and
+// grows back to the configured batch size after successful queries.
+func (l *BSCListener) catchUp(fromHeight, maxHeight uint64, latestPolledBlock *model.BscBlock) error {
+	fromHeader, err := l.bscExecutor.GetBlockHeaderAtHeight(fromHeight)
+	if err != nil {
+		return fmt.Errorf("failed to get block header at height %d, err=%s", fromHeight, err.Error())
+	}
+	if fromHeader == nil {
+		return fmt.Errorf("block header at height %d not found", fromHeight)
+	}
+	if latestPolledBlock.Height+1 == fromHeight && fromHeader.ParentHash.String() != latestPolledBlock.BlockHash {
+		return l.rollbackReorg(latestPolledBlock)
+	}
+
+	var logs []types.Log
+	toHeight := fromHeight + l.logRangeSize - 1
+	for {
+		if toHeight > maxHeight {
+			toHeight = maxHeight
+		}
+		logs, err = l.queryCrossChainLogs(fromHeight, toHeight)
+		if err == nil {
+			break
+		}
+		if toHeight == fromHeight {
+			return err
+		}
+		l.logRangeSize = (toHeight - fromHeight + 1) / 2
+		logging.Logger.Infof("failed to query logs from %d to %d, retry with %d blocks, err=%s", fromHeight, toHeight, l.logRangeSize, err.Error())
+		toHeight = fromHeight + l.logRangeSize - 1
+	}
+	if batchSize := l.config.BSCConfig.GetCatchUpBatchSize(); l.logRangeSize < batchSize {
+		l.logRangeSize *= 2
+		if l.logRangeSize > batchSize {
+			l.logRangeSize = batchSize
+		}
+	}
+	logging.Logger.Infof("scanned BSC blocks from %d to %d, found %d logs", fromHeight, toHeight, len(logs))
+
+	headers := map[uint64]*types.Header{fromHeight: fromHeader}
+	getHeader := func(height uint64) (*types.Header, error) {
+		if header, ok := headers[height]; ok {
+			return header, nil
+		}
+		header, err := l.bscExecutor.GetBlockHeaderAtHeight(height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block header at height %d, err=%s", height, err.Error())
+		}
+		if header == nil {
+			return nil, fmt.Errorf("block header at height %d not found", height)
+		}
+		headers[height] = header
+		return header, nil
+	}
+
+	blocks := make([]*model.BscBlock, 0)
+	relayPkgs := make([]*model.BscRelayPackage, 0)
+	for i := range logs {
+		log := logs[i]
+		header, err := getHeader(log.BlockNumber)
+		if err != nil {
+			return err
+		}
+		if log.BlockHash != header.Hash() {
+			return fmt.Errorf("block at height %d changed during the scan", log.BlockNumber)
+		}
+		if len(blocks) == 0 || blocks[len(blocks)-1].Height != log.BlockNumber {
+			blocks = append(blocks, newBscBlock(header))
+		}
+		relayPkg, err := ParseRelayPackage(&l.crossChainAbi,
+			&log, header.Time,
+			sdk.ChainID(l.config.GreenfieldConfig.ChainId),
+			sdk.ChainID(l.config.BSCConfig.ChainId),
+		)
+		if err != nil {
+			logging.Logger.Errorf("failed to parse event log, txHash=%s, err=%s", log.TxHash, err.Error())
+			continue
+		}
+		if relayPkg == nil {
+			continue
+		}
+		relayPkgs = append(relayPkgs, relayPkg)
+	}
+	// the last scanned block is saved to resume from, even if it has no packages
+	if len(blocks) == 0 || blocks[len(blocks)-1].Height != toHeight {
+		header, err := getHeader(toHeight)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, newBscBlock(header))
+	}
+
+	if err = l.DaoManager.BSCDao.SaveBlocksAndBatchPackages(blocks, relayPkgs); err != nil {
+		return fmt.Errorf("failed to persist blocks and packages to DB, err=%s", err.Error())
+	}
+	l.monitorService.SetBSCSavedBlockHeight(toHeight)
+	return nil
+}
+
+func newBscBlock(header *types.Header) *model.BscBlock {
+	return &model.BscBlock{
+		BlockHash:  header.Hash().String(),
+		ParentHash: header.ParentHash.String(),
+		Height:     header.Number.Uint64(),
+		BlockTime:  int64(header.Time),
+	}
+}
+
+func (l *BSCListener) queryCrossChainLogs(fromHeight, toHeight uint64) ([]types.Log, error) {
 	client := l.bscExecutor.GetEthClient()
 	topics := [][]ethcommon.Hash{{l.getCrossChainPackageEventHash()}}
 	logs, err := client.FilterLogs(context.Background(), ethereum.FilterQuery{
-		FromBlock: big.NewInt(int64(height)),
-		ToBlock:   big.NewInt(int64(height)),
+		FromBlock: big.NewInt(int64(fromHeight)),
+		ToBlock:   big.NewInt(int64(toHeight)),
 		Topics:    topics,
 		Addresses: []ethcommon.Address{l.getCrossChainContractAddress()},
 	})
@@ -177,7 +363,7 @@ func (l *BSCListener) PurgeLoop() {
 		if blockHeightThreshHold <= 0 {
 			continue
 		}
-		if err = l.DaoManager.BSCDao.DeleteBlocksBelowHeight(blockHeightThreshHold); err != nil {
+		if _, err = l.DaoManager.BSCDao.DeleteBlocksBelowHeight(blockHeightThreshHold); err != nil {
 			logging.Logger.Errorf("failed to delete Bsc blocks, err=%s", err.Error())
 			continue
 		}
@@ -185,7 +371,7 @@ func (l *BSCListener) PurgeLoop() {
 		if err != nil || exists {
 			continue
 		}
-		if err = l.DaoManager.BSCDao.DeletePackagesBelowHeightWithLimit(blockHeightThreshHold, DeletionLimit); err != nil {
+		if _, err = l.DaoManager.BSCDao.DeletePackagesBelowHeightWithLimit(blockHeightThreshHold, DeletionLimit); err != nil {
 			logging.Logger.Errorf("failed to delete bsc packages, err=%s", err.Error())
 			continue
 		}
@@ -195,7 +381,7 @@ func (l *BSCListener) PurgeLoop() {
 		} else {
 			eventType = votepool.FromBscCrossChainEvent
 		}
-		if err = l.DaoManager.VoteDao.DeleteVotesBelowHeightWithLimit(blockHeightThreshHold, uint32(eventType), DeletionLimit); err != nil {
+		if _, err = l.DaoManager.VoteDao.DeleteVotesBelowHeightWithLimit(blockHeightThreshHold, uint32(eventType), DeletionLimit); err != nil {
 			logging.Logger.Errorf("failed to delete votes, err=%s", err.Error())
 		}
 	}
@@ -0,0 +1,72 @@
+package listener
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-relayer/db/model"
+)
+
+// fakeBSC holds the stored blocks by height and the canonical block hashes of the chain.
+type fakeBSC struct {
+	stored    map[uint64]*model.BscBlock
+	canonical map[uint64]string
+}
+
+func (f *fakeBSC) getBlockBelow(height uint64) (*model.BscBlock, error) {
+	for h := int64(height) - 1; h >= 0; h-- {
+		if b, ok := f.stored[uint64(h)]; ok {
+			return b, nil
+		}
+	}
+	return &model.BscBlock{}, nil
+}
+
+func (f *fakeBSC) getCanonicalHash(height uint64) (string, bool, error) {
+	hash, ok := f.canonical[height]
+	return hash, ok, nil
+}
+
+func TestFindCommonAncestorAcrossGap(t *testing.T) {
+	f := &fakeBSC{
+		stored: map[uint64]*model.BscBlock{
+			100: {Height: 100, BlockHash: "0x100"},
+			150: {Height: 150, BlockHash: "0x150"},
+			200: {Height: 200, BlockHash: "0x200-orphaned"},
+			201: {Height: 201, BlockHash: "0x201-orphaned"},
+		},
+		canonical: map[uint64]string{100: "0x100", 150: "0x150", 200: "0x200", 201: "0x201"},
+	}
+
+	// the heights between 150 and 200 are not stored, the walk goes on to the stored ancestor
+	height, err := findCommonAncestor(f.stored[201], f.getBlockBelow, f.getCanonicalHash)
+	require.NoError(t, err)
+	require.Equal(t, uint64(150), height)
+
+	// the reorg reaches below the gap
+	f.canonical[150] = "0x150-new"
+	height, err = findCommonAncestor(f.stored[201], f.getBlockBelow, f.getCanonicalHash)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), height)
+
+	// all stored blocks are orphaned
+	f.canonical[100] = "0x100-new"
+	height, err = findCommonAncestor(f.stored[201], f.getBlockBelow, f.getCanonicalHash)
+	require.NoError(t, err)
+	require.Equal(t, uint64(99), height)
+
+	// the latest stored block is canonical
+	height, err = findCommonAncestor(&model.BscBlock{Height: 201, BlockHash: "0x201"}, f.getBlockBelow, f.getCanonicalHash)
+	require.NoError(t, err)
+	require.Equal(t, uint64(201), height)
+}
+
+func TestFindCommonAncestorTooDeep(t *testing.T) {
+	f := &fakeBSC{stored: make(map[uint64]*model.BscBlock), canonical: make(map[uint64]string)}
+	for h := uint64(0); h <= MaxBSCReorgDepth; h++ {
+		f.stored[h] = &model.BscBlock{Height: h, BlockHash: "orphaned"}
+	}
+	_, err := findCommonAncestor(f.stored[MaxBSCReorgDepth], f.getBlockBelow, f.getCanonicalHash)
+	require.Error(t, err)
+}
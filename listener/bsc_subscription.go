@@ -0,0 +1,99 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/zkMeLabs/mechain-relayer/common"
+	"github.com/zkMeLabs/mechain-relayer/logging"
+)
+
+// SubscribeLoop subscribes to the BSC new heads and cross chain logs through the websocket endpoint and re-subscribes
+// when the connection drops. The events only wake up the poll loop, blocks and logs are still read and saved by poll,
+// so the finality rules and the reorg handling are the same as in polling mode. While the subscription is down the
+// listener keeps polling every ListenerPauseTime.
+func (l *BSCListener) SubscribeLoop() {
+	for {
+		if err := l.subscribe(); err != nil {
+			logging.Logger.Errorf("BSC subscription is down, fallback to polling, err=%s", err.Error())
+		}
+		l.monitorService.SetBSCSubscribed(false)
+		time.Sleep(BSCSubscriptionRetryInterval)
+	}
+}
+
+func (l *BSCListener) subscribe() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := l.bscExecutor.DialWebsocketClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	headCh := make(chan *types.Header, 16)
+	headSub, err := client.SubscribeNewHead(ctx, headCh)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe new heads, err=%s", err.Error())
+	}
+	defer headSub.Unsubscribe()
+
+	logCh := make(chan types.Log, 16)
+	logSub, err := client.SubscribeFilterLogs(ctx, ethereum.FilterQuery{
+		Topics:    [][]ethcommon.Hash{{l.getCrossChainPackageEventHash()}},
+		Addresses: []ethcommon.Address{l.getCrossChainContractAddress()},
+	}, logCh)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe cross chain logs, err=%s", err.Error())
+	}
+	defer logSub.Unsubscribe()
+
+	logging.Logger.Infof("subscribed to BSC new heads and cross chain logs")
+	l.monitorService.SetBSCSubscribed(true)
+	for {
+		select {
+		case err = <-headSub.Err():
+			return subscriptionError("new heads", err)
+		case err = <-logSub.Err():
+			return subscriptionError("cross chain logs", err)
+		case header := <-headCh:
+			logging.Logger.Debugf("received BSC new head at height=%d", header.Number.Uint64())
+			l.notifyNewBlock()
+		case log := <-logCh:
+			// removed logs belong to a reorged block, poll detects the reorg when it reaches the block
+			logging.Logger.Debugf("received BSC cross chain log at height=%d, txHash=%s, removed=%t", log.BlockNumber, log.TxHash.String(), log.Removed)
+			l.notifyNewBlock()
+		}
+	}
+}
+
+// notifyNewBlock wakes up the poll loop if it is waiting, signals are merged while the loop is busy
+func (l *BSCListener) notifyNewBlock() {
+	select {
+	case l.newBlockCh <- struct{}{}:
+	default:
+	}
+}
+
+// waitForNewBlock pauses the poll loop until the subscription reports a new block, at most for ListenerPauseTime
+func (l *BSCListener) waitForNewBlock() {
+	timer := time.NewTimer(common.ListenerPauseTime)
+	defer timer.Stop()
+	select {
+	case <-l.newBlockCh:
+	case <-timer.C:
+	}
+}
+
+func subscriptionError(name string, err error) error {
+	if err == nil {
+		return fmt.Errorf("%s subscription is closed", name)
+	}
+	return fmt.Errorf("%s subscription failed, err=%s", name, err.Error())
+}
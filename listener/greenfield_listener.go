@@ -8,7 +8,6 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	abci "github.com/cometbft/cometbft/abci/types"
@@ -40,6 +39,7 @@ type GreenfieldListener struct {
 	DaoManager         *dao.DaoManager
 	crossChainAbi      abi.ABI
 	metricService      *metric.MetricService
+	newBlockCh         chan struct{} // signaled by the websocket subscription when a new block or cross chain event arrives
 }
 
 func NewGreenfieldListener(cfg *config.Config, gnfdExecutor *executor.GreenfieldExecutor, bscExecutor *executor.BSCExecutor,
@@ -56,10 +56,14 @@ func NewGreenfieldListener(cfg *config.Config, gnfdExecutor *executor.Greenfield
 		DaoManager:         dao,
 		crossChainAbi:      crossChainAbi,
 		metricService:      ms,
+		newBlockCh:         make(chan struct{}, 1),
 	}
 }
 
 func (l *GreenfieldListener) StartLoop() {
+	if l.config.GreenfieldConfig.UseWebsocket {
+		go l.SubscribeLoop()
+	}
 	for {
 		if err := l.poll(); err != nil {
 			logging.Logger.Errorf("encounter err, err=%s", err.Error())
@@ -69,49 +73,74 @@ func (l *GreenfieldListener) StartLoop() {
 	}
 }
 
+// greenfieldBlockData is a block fetched ahead by poll together with the relay transactions found in it
+type greenfieldBlockData struct {
+	block *tmtypes.Block
+	txs   []*model.GreenfieldRelayTransaction
+	err   error
+}
+
+// poll fetches up to prefetch_size blocks concurrently, then checks the validator changes and saves the blocks one by one
+// in height order. Blocks before a failed one are kept, the next poll resumes from the failed height.
 func (l *GreenfieldListener) poll() error {
-	nextHeight, err := l.calNextHeight()
+	nextHeight, latestHeight, err := l.calNextHeight()
 	if err != nil {
 		return fmt.Errorf("failed to cal next height, error: %s", err.Error())
 	}
-	blockResults, block, err := l.getBlockAndBlockResult(nextHeight)
-	if err != nil {
-		return fmt.Errorf("failed to get block and block result at height %d, error: %s", nextHeight, err.Error())
+	count := uint64(1)
+	if latestHeight > nextHeight {
+		count = latestHeight - nextHeight + 1
+	}
+	if prefetchSize := l.config.GreenfieldConfig.GetPrefetchSize(); count > prefetchSize {
+		count = prefetchSize
 	}
-	txs := make([]*model.GreenfieldRelayTransaction, 0)
-	wg := new(sync.WaitGroup)
-	wg.Add(3)
-	relayTxCh := make(chan *model.GreenfieldRelayTransaction)
-	errChan := make(chan error)
-	waitCh := make(chan struct{})
-
-	go func() {
-		go l.monitorTxEvents(block, blockResults.TxsResults, relayTxCh, errChan, wg)
-		go l.monitorEndBlockEvents(uint64(block.Height), blockResults.EndBlockEvents, relayTxCh, errChan, wg)
-		go l.monitorValidators(block, errChan, wg)
-		wg.Wait()
-		close(waitCh)
-	}()
 
-	for {
-		select {
-		case err := <-errChan:
-			return fmt.Errorf("encounter error when monitoring block at Height=%d, err=%s", nextHeight, err.Error())
-		case tx := <-relayTxCh:
-			txs = append(txs, tx)
-		case <-waitCh:
-			b := &model.GreenfieldBlock{
-				Chain:     block.ChainID,
-				Height:    uint64(block.Height),
-				BlockTime: block.Time.Unix(),
-			}
-			if err := l.DaoManager.GreenfieldDao.SaveBlockAndBatchTransactions(b, txs); err != nil {
-				return fmt.Errorf("failed to persist block and tx to DB, err=%s", err.Error())
-			}
-			l.metricService.SetGnfdSavedBlockHeight(uint64(block.Height))
-			return nil
+	dataChs := make([]chan *greenfieldBlockData, count)
+	for i := range dataChs {
+		dataChs[i] = make(chan *greenfieldBlockData, 1)
+		go func(height uint64, dataCh chan *greenfieldBlockData) {
+			dataCh <- l.fetchBlockData(height)
+		}(nextHeight+uint64(i), dataChs[i])
+	}
+
+	for i, dataCh := range dataChs {
+		height := nextHeight + uint64(i)
+		data := <-dataCh
+		if data.err != nil {
+			return fmt.Errorf("encounter error when monitoring block at Height=%d, err=%s", height, data.err.Error())
+		}
+		if err = l.monitorValidators(data.block); err != nil {
+			return fmt.Errorf("encounter error when monitoring validators at Height=%d, err=%s", height, err.Error())
 		}
+		b := &model.GreenfieldBlock{
+			Chain:     data.block.ChainID,
+			Height:    uint64(data.block.Height),
+			BlockTime: data.block.Time.Unix(),
+		}
+		if err = l.DaoManager.GreenfieldDao.SaveBlockAndBatchTransactions(b, data.txs); err != nil {
+			return fmt.Errorf("failed to persist block and tx to DB, err=%s", err.Error())
+		}
+		l.metricService.SetGnfdSavedBlockHeight(uint64(data.block.Height))
 	}
+	return nil
+}
+
+// fetchBlockData gets the block and block results at height and collects the relay transactions from the tx events,
+// the zkmesbt cross chain logs and the end block events. It does not touch the DB, so it can run ahead of the commits.
+func (l *GreenfieldListener) fetchBlockData(height uint64) *greenfieldBlockData {
+	blockResults, block, err := l.getBlockAndBlockResult(height)
+	if err != nil {
+		return &greenfieldBlockData{err: fmt.Errorf("failed to get block and block result at height %d, error: %s", height, err.Error())}
+	}
+	txs, err := l.monitorTxEvents(block, blockResults.TxsResults)
+	if err != nil {
+		return &greenfieldBlockData{err: err}
+	}
+	endBlockTxs, err := l.monitorEndBlockEvents(height, blockResults.EndBlockEvents)
+	if err != nil {
+		return &greenfieldBlockData{err: err}
+	}
+	return &greenfieldBlockData{block: block, txs: append(txs, endBlockTxs...)}
 }
 
 func (l *GreenfieldListener) getLatestPolledBlock() (*model.GreenfieldBlock, error) {
@@ -127,30 +156,28 @@ func (l *GreenfieldListener) getBlockAndBlockResult(height uint64) (*ctypes.Resu
 	return blockResults, block, nil
 }
 
-func (l *GreenfieldListener) monitorTxEvents(block *tmtypes.Block, txRes []*abci.ResponseDeliverTx, txChan chan *model.GreenfieldRelayTransaction, errChan chan error, wg *sync.WaitGroup) {
-	defer wg.Done()
+func (l *GreenfieldListener) monitorTxEvents(block *tmtypes.Block, txRes []*abci.ResponseDeliverTx) ([]*model.GreenfieldRelayTransaction, error) {
+	txs := make([]*model.GreenfieldRelayTransaction, 0)
 	// Cross chain Transfer events
 	for idx, tx := range txRes {
 		for _, event := range tx.Events {
 			if event.Type == GreenfieldEventTypeCrossChain {
 				relayTx, err := constructRelayTx(event, uint64(block.Height))
 				if err != nil {
-					errChan <- err
-					return
+					return nil, err
 				}
 				if relayTx.DestChainId != l.destChainId() {
 					break
 				}
 				relayTx.TxHash = hex.EncodeToString(block.Txs[idx].Hash())
-				txChan <- relayTx
+				txs = append(txs, relayTx)
 			}
 		}
 	}
 
 	logs, err := l.queryCrossChainLogs(uint64(block.Height))
 	if err != nil {
-		errChan <- err
-		return
+		return nil, err
 	}
 
 	for _, log := range logs {
@@ -164,35 +191,31 @@ func (l *GreenfieldListener) monitorTxEvents(block *tmtypes.Block, txRes []*abci
 			logging.Logger.Errorf("failed to parse event log, txHash=%s, err=%s", log.TxHash, err.Error())
 			continue
 		}
-		txChan <- relayTx
+		txs = append(txs, relayTx)
 	}
+	return txs, nil
 }
 
-func (l *GreenfieldListener) monitorEndBlockEvents(height uint64, endBlockEvents []abci.Event, txChan chan *model.GreenfieldRelayTransaction, errChan chan error, wg *sync.WaitGroup) {
-	defer wg.Done()
+func (l *GreenfieldListener) monitorEndBlockEvents(height uint64, endBlockEvents []abci.Event) ([]*model.GreenfieldRelayTransaction, error) {
+	txs := make([]*model.GreenfieldRelayTransaction, 0)
 	for _, e := range endBlockEvents {
 		if e.Type == GreenfieldEventTypeCrossChain {
 			relayTx, err := constructRelayTx(e, height)
 			if err != nil {
-				errChan <- err
-				return
+				return nil, err
 			}
 			if relayTx.DestChainId != l.destChainId() {
 				break
 			}
-			txChan <- relayTx
+			txs = append(txs, relayTx)
 		}
 	}
+	return txs, nil
 }
 
-func (l *GreenfieldListener) monitorValidators(block *tmtypes.Block, errChan chan error, wg *sync.WaitGroup) {
-	defer wg.Done()
-	if err := l.monitorValidatorsHelper(block); err != nil {
-		errChan <- err
-	}
-}
-
-func (l *GreenfieldListener) monitorValidatorsHelper(block *tmtypes.Block) error {
+// monitorValidators syncs a light block to the dest chain when the validator set changes at the block. It has to be
+// called in height order, since it compares the block with the latest synced light block.
+func (l *GreenfieldListener) monitorValidators(block *tmtypes.Block) error {
 	lightClientLatestHeight, err := l.bscExecutor.GetLightClientLatestHeight()
 	if err != nil {
 		return err
@@ -247,10 +270,11 @@ func (l *GreenfieldListener) monitorValidatorsHelper(block *tmtypes.Block) error
 	return nil
 }
 
-func (l *GreenfieldListener) calNextHeight() (uint64, error) {
+// calNextHeight returns the next height to poll and the latest block height of the chain
+func (l *GreenfieldListener) calNextHeight() (uint64, uint64, error) {
 	latestPolledBlock, err := l.getLatestPolledBlock()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get latest block from db, error: %s", err.Error())
+		return 0, 0, fmt.Errorf("failed to get latest block from db, error: %s", err.Error())
 	}
 	latestPolledBlockHeight := latestPolledBlock.Height
 
@@ -261,19 +285,21 @@ func (l *GreenfieldListener) calNextHeight() (uint64, error) {
 
 	latestBlockHeight, err := l.greenfieldExecutor.GetLatestBlockHeight()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get latest block height, error: %s", err.Error())
+		return 0, 0, fmt.Errorf("failed to get latest block height, error: %s", err.Error())
 	}
 	// pauses relayer for a bit since it already caught the newest block
 	if int64(nextHeight) >= int64(latestBlockHeight) {
-		time.Sleep(common.ListenerPauseTime)
-		return nextHeight, nil
+		l.waitForNewBlock()
+		return nextHeight, latestBlockHeight, nil
 	}
-	return nextHeight, nil
+	return nextHeight, latestBlockHeight, nil
 }
 
 func (l *GreenfieldListener) sync(nextHeight uint64, validatorsHash string) error {
 	logging.Logger.Infof("syncing tendermint light block at height %d", nextHeight)
+	l.bscExecutor.BeginRelay()
 	txHash, err := l.bscExecutor.SyncTendermintLightBlock(nextHeight)
+	l.bscExecutor.EndRelay()
 	if err != nil {
 		return fmt.Errorf("failed to sync light block at height=%d, err=%s", nextHeight, err.Error())
 	}
@@ -393,7 +419,7 @@ func (l *GreenfieldListener) PurgeLoop() {
 		if threshHold <= 0 {
 			continue
 		}
-		if err = l.DaoManager.GreenfieldDao.DeleteBlocksBelowHeight(threshHold); err != nil {
+		if _, err = l.DaoManager.GreenfieldDao.DeleteBlocksBelowHeight(threshHold); err != nil {
 			logging.Logger.Errorf("failed to delete gnfd blocks, err=%s", err.Error())
 			continue
 		}
@@ -401,7 +427,7 @@ func (l *GreenfieldListener) PurgeLoop() {
 		if err != nil || exists {
 			continue
 		}
-		if err = l.DaoManager.GreenfieldDao.DeleteTransactionsBelowHeightWithLimit(threshHold, DeletionLimit); err != nil {
+		if _, err = l.DaoManager.GreenfieldDao.DeleteTransactionsBelowHeightWithLimit(threshHold, DeletionLimit); err != nil {
 			logging.Logger.Errorf("failed to delete gnfd transactions, err=%s", err.Error())
 			continue
 		}
@@ -411,7 +437,7 @@ func (l *GreenfieldListener) PurgeLoop() {
 		} else {
 			eventType = votepool.ToBscCrossChainEvent
 		}
-		if err = l.DaoManager.VoteDao.DeleteVotesBelowHeightWithLimit(threshHold, uint32(eventType), DeletionLimit); err != nil {
+		if _, err = l.DaoManager.VoteDao.DeleteVotesBelowHeightWithLimit(threshHold, uint32(eventType), DeletionLimit); err != nil {
 			logging.Logger.Errorf("failed to delete votes, err=%s", err.Error())
 		}
 	}
@@ -0,0 +1,95 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tmtypes "github.com/cometbft/cometbft/types"
+
+	"github.com/zkMeLabs/mechain-relayer/common"
+	"github.com/zkMeLabs/mechain-relayer/logging"
+)
+
+const greenfieldSubscriber = "mechain-relayer"
+
+// greenfieldCrossChainTxQuery matches the txs which emit a cross chain event
+var greenfieldCrossChainTxQuery = fmt.Sprintf("%s AND %s.channel_id EXISTS", tmtypes.EventQueryTx.String(), GreenfieldEventTypeCrossChain)
+
+// SubscribeLoop subscribes to the new block headers and the cross chain tx events through the CometBFT websocket and
+// re-subscribes when no block header arrives for GreenfieldSubscriptionTimeout. The events only wake up the poll loop,
+// which still walks the heights one by one from the DB, so polling remains the backstop and no height is skipped.
+func (l *GreenfieldListener) SubscribeLoop() {
+	for {
+		if err := l.subscribe(); err != nil {
+			logging.Logger.Errorf("Greenfield subscription is down, fallback to polling, err=%s", err.Error())
+		}
+		l.metricService.SetGnfdSubscribed(false)
+		time.Sleep(GreenfieldSubscriptionRetryInterval)
+	}
+}
+
+func (l *GreenfieldListener) subscribe() error {
+	client, err := l.greenfieldExecutor.NewEventClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := client.Stop(); err != nil {
+			logging.Logger.Errorf("failed to stop Greenfield websocket client, err=%s", err.Error())
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), GreenfieldSubscriptionTimeout)
+	defer cancel()
+	headerCh, err := client.Subscribe(ctx, greenfieldSubscriber, tmtypes.EventQueryNewBlockHeader.String(), 16)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe new block headers, err=%s", err.Error())
+	}
+	txCh, err := client.Subscribe(ctx, greenfieldSubscriber, greenfieldCrossChainTxQuery, 16)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe cross chain events, err=%s", err.Error())
+	}
+
+	logging.Logger.Infof("subscribed to Greenfield new block headers and cross chain events")
+	l.metricService.SetGnfdSubscribed(true)
+	// the websocket client reconnects by itself and never closes the channels, a silent subscription is treated as down
+	timer := time.NewTimer(GreenfieldSubscriptionTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case event := <-headerCh:
+			if header, ok := event.Data.(tmtypes.EventDataNewBlockHeader); ok {
+				logging.Logger.Debugf("received Greenfield new block header at height=%d", header.Header.Height)
+			}
+			l.notifyNewBlock()
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(GreenfieldSubscriptionTimeout)
+		case <-txCh:
+			logging.Logger.Debugf("received Greenfield cross chain event")
+			l.notifyNewBlock()
+		case <-timer.C:
+			return fmt.Errorf("no new block header received in %s", GreenfieldSubscriptionTimeout)
+		}
+	}
+}
+
+// notifyNewBlock wakes up the poll loop if it is waiting, signals are merged while the loop is busy
+func (l *GreenfieldListener) notifyNewBlock() {
+	select {
+	case l.newBlockCh <- struct{}{}:
+	default:
+	}
+}
+
+// waitForNewBlock pauses the poll loop until the subscription reports a new block, at most for ListenerPauseTime
+func (l *GreenfieldListener) waitForNewBlock() {
+	timer := time.NewTimer(common.ListenerPauseTime)
+	defer timer.Stop()
+	select {
+	case <-l.newBlockCh:
+	case <-timer.C:
+	}
+}
@@ -17,12 +17,16 @@ const (
 	MetricNameIsGnfdInturnRelayer  = "is_Greenfield_inturn_relayer"
 	MetricNameGnfdRelayerStartTime = "Greenfield_relayer_start_time" // inturn relayer start time
 	MetricNameGnfdRelayerEndTime   = "Greenfield_relayer_end_time"   // inturn relayer end time
+	MetricNameGnfdSubscribed       = "Greenfield_subscribed"         // whether the websocket subscription is up
 
 	MetricNameBSCSavedBlock       = "BSC_saved_block_height"
 	MetricNameBSCProcessedBlock   = "BSC_processed_block_height"
 	MetricNameIsBSCInturnRelayer  = "is_BSC_inturn_relayer"
 	MetricNameBSCRelayerStartTime = "BSC_relayer_start_time" // inturn relayer start time
 	MetricNameBSCRelayerEndTime   = "BSC_relayer_end_time"   // inturn relayer end time
+	MetricNameBSCReorgDepth       = "BSC_reorg_depth"        // depth of the latest reorg
+	MetricNameBSCReorgCount       = "BSC_reorg_count"
+	MetricNameBSCSubscribed       = "BSC_subscribed" // whether the websocket subscription is up
 
 	MetricNameNextSendSequenceForChannel    = "next_send_seq_for_channel"
 	MetricNameNextReceiveSequenceForChannel = "next_receive_seq_for_channel"
@@ -30,8 +34,23 @@ const (
 	MetricNameBSCBalance = "bsc_balance"
 
 	MetricNameHasTxDelay = "tx_delay"
+
+	MetricNameVoteEquivocationCount = "vote_equivocation_count" // votes of validators over a conflicting event hash
+
+	MetricNameBSCEstimatedGas = "BSC_estimated_gas" // gas estimated for the BSC txs by method
+	MetricNameBSCGasUsed      = "BSC_gas_used"      // gas used by the included BSC txs by method
+)
+
+// methods of the BSC txs whose gas is recorded
+const (
+	BSCTxHandlePackage  = "handle_package"
+	BSCTxSyncLightBlock = "sync_light_block"
+	BSCTxClaimReward    = "claim_reward"
 )
 
+// gasBuckets range from 50k to about 4.3M gas
+var gasBuckets = prometheus.ExponentialBuckets(50000, 1.5, 12)
+
 type MetricService struct {
 	MetricsMap map[string]prometheus.Metric
 	cfg        *config.Config
@@ -111,6 +130,38 @@ func NewMetricService(config *config.Config) *MetricService {
 	ms[MetricNameIsBSCInturnRelayer] = bscIsInturnRelayerMetric
 	prometheus.MustRegister(bscIsInturnRelayerMetric)
 
+	bscReorgDepthMetric := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        MetricNameBSCReorgDepth,
+		Help:        "Number of blocks rolled back by the latest BSC reorg",
+		ConstLabels: labels,
+	})
+	ms[MetricNameBSCReorgDepth] = bscReorgDepthMetric
+	prometheus.MustRegister(bscReorgDepthMetric)
+
+	bscReorgCountMetric := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        MetricNameBSCReorgCount,
+		Help:        "Number of BSC reorgs detected",
+		ConstLabels: labels,
+	})
+	ms[MetricNameBSCReorgCount] = bscReorgCountMetric
+	prometheus.MustRegister(bscReorgCountMetric)
+
+	gnfdSubscribedMetric := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        MetricNameGnfdSubscribed,
+		Help:        "Whether relayer is subscribed to Greenfield new block headers and cross chain events",
+		ConstLabels: labels,
+	})
+	ms[MetricNameGnfdSubscribed] = gnfdSubscribedMetric
+	prometheus.MustRegister(gnfdSubscribedMetric)
+
+	bscSubscribedMetric := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        MetricNameBSCSubscribed,
+		Help:        "Whether relayer is subscribed to BSC new heads and cross chain logs",
+		ConstLabels: labels,
+	})
+	ms[MetricNameBSCSubscribed] = bscSubscribedMetric
+	prometheus.MustRegister(bscSubscribedMetric)
+
 	// BSC relayer(Greenfield -> BSC) relay interval metrics
 	bscRelayerStartTimeMetric := prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:        MetricNameBSCRelayerStartTime,
@@ -178,6 +229,38 @@ func NewMetricService(config *config.Config) *MetricService {
 	ms[MetricNameHasTxDelay] = hasTxDelayMetric
 	prometheus.MustRegister(hasTxDelayMetric)
 
+	voteEquivocationCountMetric := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        MetricNameVoteEquivocationCount,
+		Help:        "Number of validator votes recorded as evidence for signing a conflicting event hash",
+		ConstLabels: labels,
+	})
+	ms[MetricNameVoteEquivocationCount] = voteEquivocationCountMetric
+	prometheus.MustRegister(voteEquivocationCountMetric)
+
+	for _, method := range []string{BSCTxHandlePackage, BSCTxSyncLightBlock, BSCTxClaimReward} {
+		methodLabels := map[string]string{"method": method}
+		for k, v := range labels {
+			methodLabels[k] = v
+		}
+		estimatedGasMetric := prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        MetricNameBSCEstimatedGas,
+			Help:        "Gas estimated for the BSC transactions",
+			ConstLabels: methodLabels,
+			Buckets:     gasBuckets,
+		})
+		ms[fmt.Sprintf("%s_%s", MetricNameBSCEstimatedGas, method)] = estimatedGasMetric
+		prometheus.MustRegister(estimatedGasMetric)
+
+		gasUsedMetric := prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        MetricNameBSCGasUsed,
+			Help:        "Gas used by the included BSC transactions",
+			ConstLabels: methodLabels,
+			Buckets:     gasBuckets,
+		})
+		ms[fmt.Sprintf("%s_%s", MetricNameBSCGasUsed, method)] = gasUsedMetric
+		prometheus.MustRegister(gasUsedMetric)
+	}
+
 	return &MetricService{
 		MetricsMap: ms,
 		cfg:        config,
@@ -208,6 +291,27 @@ func (m *MetricService) SetBSCProcessedBlockHeight(height uint64) {
 	m.MetricsMap[MetricNameBSCProcessedBlock].(prometheus.Gauge).Set(float64(height))
 }
 
+func (m *MetricService) RecordBSCReorg(depth uint64) {
+	m.MetricsMap[MetricNameBSCReorgDepth].(prometheus.Gauge).Set(float64(depth))
+	m.MetricsMap[MetricNameBSCReorgCount].(prometheus.Counter).Inc()
+}
+
+func (m *MetricService) SetGnfdSubscribed(subscribed bool) {
+	var flag float64
+	if subscribed {
+		flag = 1
+	}
+	m.MetricsMap[MetricNameGnfdSubscribed].(prometheus.Gauge).Set(flag)
+}
+
+func (m *MetricService) SetBSCSubscribed(subscribed bool) {
+	var flag float64
+	if subscribed {
+		flag = 1
+	}
+	m.MetricsMap[MetricNameBSCSubscribed].(prometheus.Gauge).Set(flag)
+}
+
 func (m *MetricService) SetBSCInturnRelayerMetrics(isInturn bool, start, end uint64) {
 	m.setIsBSCInturnRelayer(isInturn)
 	m.setBSCInturnRelayerStartTime(start)
@@ -271,3 +375,15 @@ func (m *MetricService) SetHasTxDelay(hasDelay bool) {
 	}
 	m.MetricsMap[MetricNameHasTxDelay].(prometheus.Gauge).Set(flag)
 }
+
+func (m *MetricService) RecordVoteEquivocation() {
+	m.MetricsMap[MetricNameVoteEquivocationCount].(prometheus.Counter).Inc()
+}
+
+func (m *MetricService) RecordBSCEstimatedGas(method string, gas uint64) {
+	m.MetricsMap[fmt.Sprintf("%s_%s", MetricNameBSCEstimatedGas, method)].(prometheus.Histogram).Observe(float64(gas))
+}
+
+func (m *MetricService) RecordBSCGasUsed(method string, gas uint64) {
+	m.MetricsMap[fmt.Sprintf("%s_%s", MetricNameBSCGasUsed, method)].(prometheus.Histogram).Observe(float64(gas))
+}
@@ -33,6 +33,7 @@ func (r *BSCRelayer) Start() {
 	go r.SignAndBroadcastVoteLoop()
 	go r.CollectVotesLoop()
 	go r.AssemblePackagesLoop()
+	go r.TrackPackagesLoop()
 	go r.UpdateCachedLatestValidatorsLoop()
 	go r.UpdateClientLoop()
 	go r.ClaimRewardLoop()
@@ -56,6 +57,10 @@ func (r *BSCRelayer) AssemblePackagesLoop() {
 	r.assembler.AssemblePackagesAndClaimLoop()
 }
 
+func (r *BSCRelayer) TrackPackagesLoop() {
+	r.assembler.TrackPackagesLoop()
+}
+
 func (r *BSCRelayer) UpdateCachedLatestValidatorsLoop() {
 	r.bscExecutor.UpdateCachedLatestValidatorsLoop() // cache validators queried from greenfield, update it every 1 minute
 }
@@ -31,6 +31,7 @@ func (r *GreenfieldRelayer) Start() {
 	go r.SignAndBroadcastLoop()
 	go r.CollectVotesLoop()
 	go r.AssembleTransactionsLoop()
+	go r.TrackTransactionsLoop()
 	go r.UpdateCachedLatestValidatorsLoop()
 	go r.PurgeLoop()
 }
@@ -52,6 +53,10 @@ func (r *GreenfieldRelayer) AssembleTransactionsLoop() {
 	r.greenfieldAssembler.AssembleTransactionsLoop()
 }
 
+func (r *GreenfieldRelayer) TrackTransactionsLoop() {
+	r.greenfieldAssembler.TrackTransactionsLoop()
+}
+
 func (r *GreenfieldRelayer) UpdateCachedLatestValidatorsLoop() {
 	r.GreenfieldExecutor.UpdateCachedLatestValidatorsLoop() // cache validators queried from greenfield, update it every 1 minute
 }
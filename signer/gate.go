@@ -0,0 +1,28 @@
+package signer
+
+import "sync"
+
+// Gate keeps the relayer keys from being swapped while they are in use. Every path which signs with the relayer keys
+// enters the gate before it reads a key or a nonce and leaves it once its transactions or votes are sent. The key
+// rotation pauses the gate, which waits for the paths inside to leave and keeps new ones out until it is resumed. The
+// zero value is an open gate.
+type Gate struct {
+	mutex sync.RWMutex
+}
+
+func (g *Gate) Enter() {
+	g.mutex.RLock()
+}
+
+func (g *Gate) Leave() {
+	g.mutex.RUnlock()
+}
+
+// Pause waits for the paths inside the gate to leave and keeps new ones out until Resume is called.
+func (g *Gate) Pause() {
+	g.mutex.Lock()
+}
+
+func (g *Gate) Resume() {
+	g.mutex.Unlock()
+}
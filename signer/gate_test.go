@@ -0,0 +1,57 @@
+package signer
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGateRotationWhileSigning(t *testing.T) {
+	var (
+		gate    Gate
+		key     int // swapped by the rotation, read by the signing rounds
+		rounds  atomic.Int64
+		stop    atomic.Bool
+		wg      sync.WaitGroup
+		swapped = make(chan struct{})
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				gate.Enter()
+				before := key
+				time.Sleep(time.Millisecond)
+				// the key is never swapped in the middle of a round
+				require.Equal(t, before, key)
+				gate.Leave()
+				rounds.Add(1)
+			}
+		}()
+	}
+
+	go func() {
+		for i := 0; i < 10; i++ {
+			gate.Pause()
+			key++
+			gate.Resume()
+			time.Sleep(2 * time.Millisecond)
+		}
+		close(swapped)
+	}()
+	<-swapped
+
+	// the signing rounds go on after the rotation
+	resumed := rounds.Load()
+	require.Eventually(t, func() bool { return rounds.Load() > resumed }, time.Second, time.Millisecond)
+	stop.Store(true)
+	wg.Wait()
+
+	gate.Enter()
+	require.Equal(t, 10, key)
+	gate.Leave()
+}
@@ -0,0 +1,44 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// LocalSigner signs with a private key held in memory.
+type LocalSigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+var _ Signer = (*LocalSigner)(nil)
+
+func NewLocalSigner(privateKey *ecdsa.PrivateKey) *LocalSigner {
+	return &LocalSigner{
+		privateKey: privateKey,
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}
+}
+
+// NewLocalSignerFromHex loads the hex encoded private key.
+func NewLocalSignerFromHex(hexKey string) (*LocalSigner, error) {
+	privateKey, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load private key, err=%s", err.Error())
+	}
+	return NewLocalSigner(privateKey), nil
+}
+
+func (s *LocalSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *LocalSigner) SignTx(_ context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainId), s.privateKey)
+}
@@ -0,0 +1,113 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RemoteSigner asks a signing service, such as Clef or web3signer, to sign the transactions through the
+// eth_signTransaction JSON-RPC method, so the key never has to be loaded by the relayer.
+type RemoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+var _ Signer = (*RemoteSigner)(nil)
+
+// NewRemoteSigner connects to the signing service at url, which signs for address.
+func NewRemoteSigner(ctx context.Context, url string, address common.Address) (*RemoteSigner, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial remote signer %s, err=%s", url, err.Error())
+	}
+	return &RemoteSigner{
+		client:  client,
+		address: address,
+	}, nil
+}
+
+// SignTxArgs are the arguments of eth_signTransaction.
+type SignTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                hexutil.Big     `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainId              *hexutil.Big    `json:"chainId"`
+}
+
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	args := SignTxArgs{
+		From:    s.address,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    tx.Data(),
+		ChainId: (*hexutil.Big)(chainId),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+
+	var result json.RawMessage
+	if err := s.client.CallContext(ctx, &result, "eth_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("remote signer failed to sign tx, err=%s", err.Error())
+	}
+	raw, err := decodeSignTxResult(result)
+	if err != nil {
+		return nil, err
+	}
+	signed := new(types.Transaction)
+	if err = signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode tx signed by remote signer, err=%s", err.Error())
+	}
+
+	// the signer must sign exactly the requested tx with the expected account
+	ethSigner := types.LatestSignerForChainID(chainId)
+	if ethSigner.Hash(signed) != ethSigner.Hash(tx) {
+		return nil, fmt.Errorf("remote signer signed a different tx")
+	}
+	sender, err := types.Sender(ethSigner, signed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover the sender of tx signed by remote signer, err=%s", err.Error())
+	}
+	if sender != s.address {
+		return nil, fmt.Errorf("remote signer signed with %s, expected %s", sender.String(), s.address.String())
+	}
+	return signed, nil
+}
+
+// decodeSignTxResult accepts both the raw tx returned by web3signer and the {"raw": ..., "tx": ...} object returned by
+// Clef and geth.
+func decodeSignTxResult(result json.RawMessage) ([]byte, error) {
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(result, &raw); err == nil {
+		return raw, nil
+	}
+	var signTxResult struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := json.Unmarshal(result, &signTxResult); err != nil || len(signTxResult.Raw) == 0 {
+		return nil, fmt.Errorf("unexpected eth_signTransaction result %s", string(result))
+	}
+	return signTxResult.Raw, nil
+}
@@ -0,0 +1,33 @@
+package signer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Signer signs the EVM transactions sent by a relayer account. The key can be held in memory, decrypted from a geth
+// keystore file or kept by a remote signing service.
+type Signer interface {
+	// Address returns the account the transactions are signed for
+	Address() common.Address
+	// SignTx returns tx signed for the chain with chainId
+	SignTx(ctx context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error)
+}
+
+// NewTransactor returns the transact options for the contract bindings, which sign the transactions with s.
+func NewTransactor(ctx context.Context, s Signer, chainId *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: s.Address(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != s.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(ctx, tx, chainId)
+		},
+		Context: ctx,
+	}
+}
@@ -0,0 +1,103 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+var testChainId = big.NewInt(97)
+
+// testSignerService stands in for a remote signer and signs every request with key.
+type testSignerService struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *testSignerService) SignTransaction(args SignTxArgs) (hexutil.Bytes, error) {
+	var txData types.TxData
+	if args.MaxFeePerGas != nil {
+		txData = &types.DynamicFeeTx{
+			ChainID:   args.ChainId.ToInt(),
+			Nonce:     uint64(args.Nonce),
+			GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+			GasFeeCap: args.MaxFeePerGas.ToInt(),
+			Gas:       uint64(args.Gas),
+			To:        args.To,
+			Value:     args.Value.ToInt(),
+			Data:      args.Data,
+		}
+	} else {
+		txData = &types.LegacyTx{
+			Nonce:    uint64(args.Nonce),
+			GasPrice: args.GasPrice.ToInt(),
+			Gas:      uint64(args.Gas),
+			To:       args.To,
+			Value:    args.Value.ToInt(),
+			Data:     args.Data,
+		}
+	}
+	tx, err := types.SignNewTx(s.key, types.LatestSignerForChainID(args.ChainId.ToInt()), txData)
+	if err != nil {
+		return nil, err
+	}
+	return tx.MarshalBinary()
+}
+
+func newTestRemoteSigner(t *testing.T, serverKey *ecdsa.PrivateKey, address common.Address) *RemoteSigner {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", &testSignerService{key: serverKey}))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	s, err := NewRemoteSigner(context.Background(), httpServer.URL, address)
+	require.NoError(t, err)
+	return s
+}
+
+func newTestTx() *types.Transaction {
+	to := common.HexToAddress("0x1234")
+	return types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(5), Gas: 21000, To: &to, Value: big.NewInt(0), Data: []byte{1}})
+}
+
+func checkSignedTx(t *testing.T, s Signer, tx *types.Transaction) {
+	signed, err := NewTransactor(context.Background(), s, testChainId).Signer(s.Address(), tx)
+	require.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(testChainId), signed)
+	require.NoError(t, err)
+	require.Equal(t, s.Address(), sender)
+	require.Equal(t, tx.Nonce(), signed.Nonce())
+}
+
+func TestRemoteSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	s := newTestRemoteSigner(t, key, crypto.PubkeyToAddress(key.PublicKey))
+	checkSignedTx(t, s, newTestTx())
+
+	to := common.HexToAddress("0x1234")
+	checkSignedTx(t, s, types.NewTx(&types.DynamicFeeTx{
+		ChainID: testChainId, Nonce: 2, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(0),
+	}))
+}
+
+func TestRemoteSignerRejectsOtherAccount(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	s := newTestRemoteSigner(t, otherKey, crypto.PubkeyToAddress(key.PublicKey))
+
+	_, err = s.SignTx(context.Background(), newTestTx(), testChainId)
+	require.Error(t, err)
+}
@@ -35,8 +35,3 @@ type SequenceStatus struct {
 	HasRetrieved    bool
 	NextDeliverySeq uint64
 }
-
-type NonceStatus struct {
-	HasRetrieved bool
-	Nonce        uint64
-}
@@ -25,18 +25,21 @@ import (
 	"github.com/zkMeLabs/mechain-relayer/executor"
 	"github.com/zkMeLabs/mechain-relayer/logging"
 	"github.com/zkMeLabs/mechain-relayer/types"
+	"github.com/zkMeLabs/mechain-relayer/vote/evidence"
 )
 
 type BSCVoteProcessor struct {
-	daoManager   *dao.DaoManager
-	config       *config.Config
-	signer       *VoteSigner
-	bscExecutor  *executor.BSCExecutor
-	blsPublicKey []byte
-	eventType    votepool.EventType
+	daoManager       *dao.DaoManager
+	config           *config.Config
+	signer           *VoteSigner
+	bscExecutor      *executor.BSCExecutor
+	eventType        votepool.EventType
+	evidenceRecorder *evidence.Recorder
 }
 
-func NewBSCVoteProcessor(cfg *config.Config, dao *dao.DaoManager, signer *VoteSigner, bscExecutor *executor.BSCExecutor) *BSCVoteProcessor {
+func NewBSCVoteProcessor(cfg *config.Config, dao *dao.DaoManager, signer *VoteSigner, bscExecutor *executor.BSCExecutor,
+	evidenceRecorder *evidence.Recorder,
+) *BSCVoteProcessor {
 	var eventType votepool.EventType
 	if cfg.BSCConfig.IsOpCrossChain() {
 		eventType = votepool.FromOpCrossChainEvent
@@ -44,19 +47,22 @@ func NewBSCVoteProcessor(cfg *config.Config, dao *dao.DaoManager, signer *VoteSi
 		eventType = votepool.FromBscCrossChainEvent
 	}
 	return &BSCVoteProcessor{
-		config:       cfg,
-		daoManager:   dao,
-		signer:       signer,
-		bscExecutor:  bscExecutor,
-		blsPublicKey: bscExecutor.GreenfieldExecutor.BlsPubKey,
-		eventType:    eventType,
+		config:           cfg,
+		daoManager:       dao,
+		signer:           signer,
+		bscExecutor:      bscExecutor,
+		eventType:        eventType,
+		evidenceRecorder: evidenceRecorder,
 	}
 }
 
 func (p *BSCVoteProcessor) SignAndBroadcastVoteLoop() {
 	ticker := time.NewTicker(time.Duration(p.config.VotePoolConfig.BroadcastIntervalInMillisecond) * time.Millisecond)
 	for range ticker.C {
-		if err := p.signAndBroadcast(); err != nil {
+		p.bscExecutor.BeginRelay()
+		err := p.signAndBroadcast()
+		p.bscExecutor.EndRelay()
+		if err != nil {
 			logging.Logger.Errorf("encounter error, err: %s", err.Error())
 		}
 	}
@@ -160,7 +166,10 @@ func (p *BSCVoteProcessor) signAndBroadcast() error {
 		}
 		eventHash := blsClaim.GetSignBytes()
 		channelId := common.OracleChannelId
-		v := p.constructSignedVote(eventHash[:])
+		v, err := p.constructSignedVote(eventHash[:], uint8(channelId), seq)
+		if err != nil {
+			return err
+		}
 
 		// broadcast v
 		if err = retry.Do(func() error {
@@ -269,7 +278,7 @@ func (p *BSCVoteProcessor) collectVoteForPackages(pkgsForSeq []*model.BscRelayPa
 
 // prepareEnoughValidVotesForPackages will prepare fetch and validate votes result, store in votes
 func (p *BSCVoteProcessor) prepareEnoughValidVotesForPackages(channelId types.ChannelId, sequence uint64, pkgIds []int64) error {
-	localVote, err := p.daoManager.VoteDao.GetVoteByChannelIdAndSequenceAndPubKey(uint8(channelId), sequence, hex.EncodeToString(p.blsPublicKey))
+	localVote, err := p.daoManager.VoteDao.GetVoteByChannelIdAndSequenceAndPubKey(uint8(channelId), sequence, hex.EncodeToString(p.signer.PubKey()))
 	if err != nil {
 		return err
 	}
@@ -327,12 +336,13 @@ func (p *BSCVoteProcessor) queryMoreThanTwoThirdValidVotes(localVote *model.Vote
 				continue
 			}
 
+			p.evidenceRecorder.RecordIfEquivocation(v, localVote)
 			if err = VerifySignature(v, localVote.EventHash[:]); err != nil {
 				validVotesCntPerReq--
 				continue
 			}
 
-			if bytes.Equal(v.PubKey[:], p.blsPublicKey) {
+			if bytes.Equal(v.PubKey[:], p.signer.PubKey()) {
 				isLocalVoteIncluded = true
 				validVotesCntPerReq--
 				continue
@@ -366,12 +376,14 @@ func (p *BSCVoteProcessor) queryMoreThanTwoThirdValidVotes(localVote *model.Vote
 	return nil
 }
 
-func (p *BSCVoteProcessor) constructSignedVote(eventHash []byte) *votepool.Vote {
+func (p *BSCVoteProcessor) constructSignedVote(eventHash []byte, channelId uint8, sequence uint64) (*votepool.Vote, error) {
 	var v votepool.Vote
 	v.EventType = p.eventType
 	v.EventHash = eventHash
-	p.signer.SignVote(&v)
-	return &v
+	if err := p.signer.SignVote(&v, channelId, sequence); err != nil {
+		return nil, err
+	}
+	return &v, nil
 }
 
 func (p *BSCVoteProcessor) isVotePubKeyValid(v *votepool.Vote, validators []*tmtypes.Validator) bool {
@@ -0,0 +1,126 @@
+// Package evidence records the votes of validators which signed conflicting event hashes for the same channel and
+// sequence.
+package evidence
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0xPolygon/polygon-edge/bls"
+	"github.com/cometbft/cometbft/votepool"
+	"gorm.io/gorm"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/db/dao"
+	"github.com/zkMeLabs/mechain-relayer/db/model"
+	"github.com/zkMeLabs/mechain-relayer/logging"
+	"github.com/zkMeLabs/mechain-relayer/metric"
+)
+
+// Recorder records the votes of validators which signed an event hash other than the locally computed one for the
+// same channel and sequence, so they can be reported for governance or slashing proposals.
+type Recorder struct {
+	config        *config.Config
+	daoManager    *dao.DaoManager
+	metricService *metric.MetricService
+}
+
+func NewRecorder(cfg *config.Config, dao *dao.DaoManager, metricService *metric.MetricService) *Recorder {
+	return &Recorder{
+		config:        cfg,
+		daoManager:    dao,
+		metricService: metricService,
+	}
+}
+
+// RecordIfEquivocation checks a vote received for the channel and sequence of the local vote against the votes of the
+// same validator key already known for them. The vote is recorded as evidence if it carries another event hash than
+// the local vote, and the stored vote of the key is recorded if it was over another event hash than the received one.
+// Only votes with a valid signature over the event hash they carry are recorded, a vote with an invalid signature can
+// not be attributed to the validator and is only dropped.
+func (r *Recorder) RecordIfEquivocation(v *votepool.Vote, localVote *model.Vote) {
+	if err := verifySignature(v.PubKey, v.Signature, v.EventHash); err != nil {
+		return
+	}
+	pubKey := hex.EncodeToString(v.PubKey[:])
+	if !bytes.Equal(v.EventHash, localVote.EventHash) {
+		r.record(uint32(v.EventType), pubKey, v.EventHash, v.Signature, localVote)
+		return
+	}
+	stored, err := r.daoManager.VoteDao.GetVoteByChannelIdAndSequenceAndPubKey(localVote.ChannelId, localVote.Sequence, pubKey)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logging.Logger.Errorf("failed to get stored vote of %s, err=%s", pubKey, err.Error())
+		}
+		return
+	}
+	if bytes.Equal(stored.EventHash, v.EventHash) {
+		return
+	}
+	signature, err := hex.DecodeString(stored.Signature)
+	if err != nil {
+		logging.Logger.Errorf("failed to decode stored vote signature of %s, err=%s", pubKey, err.Error())
+		return
+	}
+	if err = verifySignature(v.PubKey, signature, stored.EventHash); err != nil {
+		return
+	}
+	r.record(uint32(v.EventType), pubKey, stored.EventHash, signature, localVote)
+}
+
+// record saves the vote of pubKey over conflictingEventHash as evidence against the local vote, unless it is recorded
+// already.
+func (r *Recorder) record(eventType uint32, pubKey string, conflictingEventHash, signature []byte, localVote *model.Vote) {
+	conflictingHash := hex.EncodeToString(conflictingEventHash)
+	exist, err := r.daoManager.VoteDao.IsVoteEvidenceExist(eventType, localVote.ChannelId, localVote.Sequence, pubKey, conflictingHash)
+	if err != nil {
+		logging.Logger.Errorf("failed to check vote evidence, err=%s", err.Error())
+		return
+	}
+	if exist {
+		return
+	}
+	evidence := &model.VoteEvidence{
+		EventType:            eventType,
+		ChannelId:            localVote.ChannelId,
+		Sequence:             localVote.Sequence,
+		PubKey:               pubKey,
+		ConflictingEventHash: conflictingHash,
+		ConflictingSignature: hex.EncodeToString(signature),
+		EventHash:            hex.EncodeToString(localVote.EventHash),
+		LocalPubKey:          localVote.PubKey,
+		LocalSignature:       localVote.Signature,
+		Height:               localVote.Height,
+		CreatedTime:          time.Now().Unix(),
+	}
+	if err = r.daoManager.VoteDao.SaveVoteEvidence(evidence); err != nil {
+		logging.Logger.Errorf("failed to save vote evidence, err=%s", err.Error())
+		return
+	}
+	r.metricService.RecordVoteEquivocation()
+
+	msg := fmt.Sprintf("validator %s signed event hash %s instead of %s for event type %d, channel %d and sequence %d",
+		pubKey, conflictingHash, evidence.EventHash, eventType, localVote.ChannelId, localVote.Sequence)
+	logging.Logger.Error(msg)
+	config.SendTelegramMessage(r.config.AlertConfig.Identity, r.config.AlertConfig.TelegramBotId,
+		r.config.AlertConfig.TelegramChatId, msg)
+}
+
+// verifySignature verifies the BLS signature of pubKey over eventHash.
+func verifySignature(pubKey, signature, eventHash []byte) error {
+	blsPubKey, err := bls.UnmarshalPublicKey(pubKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key, err=%s", err.Error())
+	}
+	sig, err := bls.UnmarshalSignature(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature, err=%s", err.Error())
+	}
+	if !sig.Verify(blsPubKey, eventHash, votepool.DST) {
+		return errors.New("verify bls signature failed")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package evidence
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/0xPolygon/polygon-edge/bls"
+	"github.com/cometbft/cometbft/votepool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/db/dao"
+	"github.com/zkMeLabs/mechain-relayer/db/dbtest"
+	"github.com/zkMeLabs/mechain-relayer/db/migration"
+	"github.com/zkMeLabs/mechain-relayer/db/model"
+	"github.com/zkMeLabs/mechain-relayer/metric"
+)
+
+func signVote(t *testing.T, key *bls.PrivateKey, eventHash []byte) *votepool.Vote {
+	sig, err := key.Sign(eventHash, votepool.DST)
+	require.NoError(t, err)
+	sigBytes, err := sig.Marshal()
+	require.NoError(t, err)
+	return &votepool.Vote{
+		PubKey:    key.PublicKey().Marshal(),
+		Signature: sigBytes,
+		EventType: votepool.FromBscCrossChainEvent,
+		EventHash: eventHash,
+	}
+}
+
+func toModelVote(v *votepool.Vote, channelId uint8, sequence uint64) *model.Vote {
+	return &model.Vote{
+		PubKey:       hex.EncodeToString(v.PubKey),
+		Signature:    hex.EncodeToString(v.Signature),
+		EventType:    uint32(v.EventType),
+		ClaimPayload: []byte("payload"),
+		EventHash:    v.EventHash,
+		ChannelId:    channelId,
+		Sequence:     sequence,
+		Height:       10,
+	}
+}
+
+func TestRecordIfEquivocation(t *testing.T) {
+	relayerDB := dbtest.NewSqliteDB(t)
+	_, err := migration.NewMigrator(relayerDB).Up()
+	require.NoError(t, err)
+	voteDao := dao.NewVoteDao(relayerDB)
+	cfg := &config.Config{}
+	recorder := NewRecorder(cfg, dao.NewDaoManager(nil, nil, voteDao), metric.NewMetricService(cfg))
+
+	keys, err := bls.CreateRandomBlsKeys(4)
+	require.NoError(t, err)
+	localHash := []byte("local event hash of the sequence")
+	conflictingHash := []byte("another event hash for sequence")
+	const channelId, sequence = 1, 7
+	localVote := toModelVote(signVote(t, keys[0], localHash), channelId, sequence)
+
+	// a vote over the local event hash is no evidence
+	recorder.RecordIfEquivocation(signVote(t, keys[1], localHash), localVote)
+
+	// a validly signed vote over another event hash is recorded
+	conflicting := signVote(t, keys[2], conflictingHash)
+	recorder.RecordIfEquivocation(conflicting, localVote)
+	// and only once
+	recorder.RecordIfEquivocation(conflicting, localVote)
+
+	// a vote signed over another hash than the one it carries can not be attributed
+	forged := signVote(t, keys[1], conflictingHash)
+	forged.EventHash = []byte("a third event hash of sequence")
+	recorder.RecordIfEquivocation(forged, localVote)
+
+	// a key whose stored vote for the sequence is over another event hash than its received vote
+	require.NoError(t, voteDao.SaveVote(toModelVote(signVote(t, keys[3], conflictingHash), channelId, sequence)))
+	recorder.RecordIfEquivocation(signVote(t, keys[3], localHash), localVote)
+
+	evidences, err := voteDao.GetVoteEvidences(0)
+	require.NoError(t, err)
+	require.Len(t, evidences, 2)
+	for i, key := range []*bls.PrivateKey{keys[2], keys[3]} {
+		e := evidences[i]
+		require.Equal(t, hex.EncodeToString(key.PublicKey().Marshal()), e.PubKey)
+		require.Equal(t, uint8(channelId), e.ChannelId)
+		require.Equal(t, uint64(sequence), e.Sequence)
+		require.Equal(t, hex.EncodeToString(conflictingHash), e.ConflictingEventHash)
+		require.Equal(t, hex.EncodeToString(localHash), e.EventHash)
+		require.Equal(t, localVote.Signature, e.LocalSignature)
+
+		signature, err := hex.DecodeString(e.ConflictingSignature)
+		require.NoError(t, err)
+		require.NoError(t, verifySignature(key.PublicKey().Marshal(), signature, conflictingHash))
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/zkMeLabs/mechain-relayer/logging"
 	"github.com/zkMeLabs/mechain-relayer/types"
 	"github.com/zkMeLabs/mechain-relayer/util"
+	"github.com/zkMeLabs/mechain-relayer/vote/evidence"
 )
 
 type GreenfieldVoteProcessor struct {
@@ -33,12 +34,12 @@ type GreenfieldVoteProcessor struct {
 	config             *config.Config
 	signer             *VoteSigner
 	greenfieldExecutor *executor.GreenfieldExecutor
-	blsPublicKey       []byte
 	eventType          votepool.EventType
+	evidenceRecorder   *evidence.Recorder
 }
 
 func NewGreenfieldVoteProcessor(cfg *config.Config, dao *dao.DaoManager, signer *VoteSigner,
-	greenfieldExecutor *executor.GreenfieldExecutor,
+	greenfieldExecutor *executor.GreenfieldExecutor, evidenceRecorder *evidence.Recorder,
 ) *GreenfieldVoteProcessor {
 	var eventType votepool.EventType
 	if cfg.BSCConfig.IsOpCrossChain() {
@@ -51,8 +52,8 @@ func NewGreenfieldVoteProcessor(cfg *config.Config, dao *dao.DaoManager, signer
 		daoManager:         dao,
 		signer:             signer,
 		greenfieldExecutor: greenfieldExecutor,
-		blsPublicKey:       greenfieldExecutor.BlsPubKey,
 		eventType:          eventType,
+		evidenceRecorder:   evidenceRecorder,
 	}
 }
 
@@ -60,7 +61,10 @@ func NewGreenfieldVoteProcessor(cfg *config.Config, dao *dao.DaoManager, signer
 func (p *GreenfieldVoteProcessor) SignAndBroadcastLoop() {
 	ticker := time.NewTicker(time.Duration(p.config.VotePoolConfig.BroadcastIntervalInMillisecond) * time.Millisecond)
 	for range ticker.C {
-		if err := p.signAndBroadcast(); err != nil {
+		p.greenfieldExecutor.BeginRelay()
+		err := p.signAndBroadcast()
+		p.greenfieldExecutor.EndRelay()
+		if err != nil {
 			logging.Logger.Errorf("encounter error, err: %s", err.Error())
 		}
 	}
@@ -95,7 +99,10 @@ func (p *GreenfieldVoteProcessor) signAndBroadcast() error {
 		if err != nil {
 			return err
 		}
-		v := p.constructVoteAndSign(aggregatedPayload)
+		v, err := p.constructVoteAndSign(aggregatedPayload, tx.ChannelId, tx.Sequence)
+		if err != nil {
+			return err
+		}
 
 		// broadcast v
 		if err = retry.Do(func() error {
@@ -199,7 +206,7 @@ func (p *GreenfieldVoteProcessor) collectVoteForTx(tx *model.GreenfieldRelayTran
 
 // prepareEnoughValidVotesForTx fetches and validate votes result, store in vote table
 func (p *GreenfieldVoteProcessor) prepareEnoughValidVotesForTx(tx *model.GreenfieldRelayTransaction) error {
-	localVote, err := p.daoManager.VoteDao.GetVoteByChannelIdAndSequenceAndPubKey(tx.ChannelId, tx.Sequence, hex.EncodeToString(p.blsPublicKey))
+	localVote, err := p.daoManager.VoteDao.GetVoteByChannelIdAndSequenceAndPubKey(tx.ChannelId, tx.Sequence, hex.EncodeToString(p.signer.PubKey()))
 	if err != nil {
 		return err
 	}
@@ -256,13 +263,14 @@ func (p *GreenfieldVoteProcessor) queryMoreThanTwoThirdVotesForTx(localVote *mod
 				continue
 			}
 
+			p.evidenceRecorder.RecordIfEquivocation(v, localVote)
 			if err = VerifySignature(v, localVote.EventHash); err != nil {
 				validVotesCountPerReq--
 				continue
 			}
 
 			// check if it is local vote
-			if bytes.Equal(v.PubKey[:], p.blsPublicKey) {
+			if bytes.Equal(v.PubKey[:], p.signer.PubKey()) {
 				isLocalVoteIncluded = true
 				validVotesCountPerReq--
 				continue
@@ -299,12 +307,14 @@ func (p *GreenfieldVoteProcessor) queryMoreThanTwoThirdVotesForTx(localVote *mod
 	return nil
 }
 
-func (p *GreenfieldVoteProcessor) constructVoteAndSign(aggregatedPayload []byte) *votepool.Vote {
+func (p *GreenfieldVoteProcessor) constructVoteAndSign(aggregatedPayload []byte, channelId uint8, sequence uint64) (*votepool.Vote, error) {
 	var v votepool.Vote
 	v.EventType = p.eventType
 	v.EventHash = p.getEventHash(aggregatedPayload)
-	p.signer.SignVote(&v)
-	return &v
+	if err := p.signer.SignVote(&v, channelId, sequence); err != nil {
+		return nil, err
+	}
+	return &v, nil
 }
 
 func (p *GreenfieldVoteProcessor) getEventHash(aggregatedPayload []byte) []byte {
@@ -0,0 +1,119 @@
+package protection
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InterchangeFormatVersion is the version of the interchange format written by Export.
+const InterchangeFormatVersion = "1"
+
+// Interchange is the file format used to move the journal between relayers, modeled on the Ethereum validator
+// slashing protection interchange format (EIP-3076).
+type Interchange struct {
+	Metadata InterchangeMetadata `json:"metadata"`
+	Data     []InterchangeKey    `json:"data"`
+}
+
+type InterchangeMetadata struct {
+	InterchangeFormatVersion string `json:"interchange_format_version"`
+}
+
+// InterchangeKey holds the votes signed by one BLS key.
+type InterchangeKey struct {
+	PubKey      string                  `json:"pubkey"`
+	SignedVotes []InterchangeSignedVote `json:"signed_votes"`
+}
+
+type InterchangeSignedVote struct {
+	EventType uint32 `json:"event_type"`
+	ChannelId uint8  `json:"channel_id"`
+	Sequence  string `json:"sequence"` // decimal string, as in EIP-3076
+	EventHash string `json:"event_hash"`
+}
+
+// Export writes all signed votes in the interchange format.
+func (j *Journal) Export(w io.Writer) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	votes := make([]*SignedVote, 0)
+	if err := j.db.Order("pub_key, event_type, channel_id, sequence").Find(&votes).Error; err != nil {
+		return err
+	}
+	interchange := Interchange{
+		Metadata: InterchangeMetadata{InterchangeFormatVersion: InterchangeFormatVersion},
+		Data:     make([]InterchangeKey, 0),
+	}
+	for _, v := range votes {
+		if len(interchange.Data) == 0 || interchange.Data[len(interchange.Data)-1].PubKey != v.PubKey {
+			interchange.Data = append(interchange.Data, InterchangeKey{PubKey: v.PubKey})
+		}
+		key := &interchange.Data[len(interchange.Data)-1]
+		key.SignedVotes = append(key.SignedVotes, InterchangeSignedVote{
+			EventType: v.EventType,
+			ChannelId: v.ChannelId,
+			Sequence:  strconv.FormatUint(v.Sequence, 10),
+			EventHash: v.EventHash,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&interchange)
+}
+
+// Import merges the signed votes in the interchange format into the journal and returns the number of new records.
+// Nothing is imported if any vote conflicts with a recorded one.
+func (j *Journal) Import(r io.Reader) (int, error) {
+	var interchange Interchange
+	if err := json.NewDecoder(r).Decode(&interchange); err != nil {
+		return 0, fmt.Errorf("failed to decode interchange file, err=%s", err.Error())
+	}
+	if interchange.Metadata.InterchangeFormatVersion != InterchangeFormatVersion {
+		return 0, fmt.Errorf("unsupported interchange format version %q", interchange.Metadata.InterchangeFormatVersion)
+	}
+
+	votes := make([]*SignedVote, 0)
+	now := time.Now().Unix()
+	for _, key := range interchange.Data {
+		for _, v := range key.SignedVotes {
+			seq, err := strconv.ParseUint(v.Sequence, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid sequence %q of key %s", v.Sequence, key.PubKey)
+			}
+			votes = append(votes, &SignedVote{
+				PubKey:      key.PubKey,
+				EventType:   v.EventType,
+				ChannelId:   v.ChannelId,
+				Sequence:    seq,
+				EventHash:   v.EventHash,
+				CreatedTime: now,
+			})
+		}
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	imported := 0
+	err := j.db.Transaction(func(tx *gorm.DB) error {
+		for _, v := range votes {
+			created, err := checkAndRecord(tx, v)
+			if err != nil {
+				return err
+			}
+			if created {
+				imported++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return imported, nil
+}
@@ -0,0 +1,100 @@
+package protection
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zkMeLabs/mechain-relayer/config"
+	"github.com/zkMeLabs/mechain-relayer/db"
+)
+
+// ErrConflictingVote is returned when a key is asked to sign a different event hash for a vote it has already signed.
+var ErrConflictingVote = errors.New("conflicting vote")
+
+// SignedVote records that the BLS key PubKey signed EventHash for the event type, channel and sequence.
+type SignedVote struct {
+	Id          int64
+	PubKey      string `gorm:"NOT NULL;uniqueIndex:idx_signed_vote_key;size:96"`
+	EventType   uint32 `gorm:"NOT NULL;uniqueIndex:idx_signed_vote_key"`
+	ChannelId   uint8  `gorm:"NOT NULL;uniqueIndex:idx_signed_vote_key"`
+	Sequence    uint64 `gorm:"NOT NULL;uniqueIndex:idx_signed_vote_key"`
+	EventHash   string `gorm:"NOT NULL"`
+	CreatedTime int64  `gorm:"NOT NULL"`
+}
+
+func (*SignedVote) TableName() string {
+	return "signed_vote"
+}
+
+// Journal is the slashing protection DB of the vote signer. It is a sqlite file kept apart from the relayer DB, so
+// restoring the relayer DB from a backup does not make the signer forget what it has signed.
+type Journal struct {
+	mutex sync.Mutex
+	db    *gorm.DB
+}
+
+// OpenJournal opens or creates the journal at path.
+func OpenJournal(path string) (*Journal, error) {
+	journalDB, err := db.OpenDB(&config.DBConfig{
+		Dialect:      config.DBDialectSqlite3,
+		Url:          path,
+		MaxIdleConns: 1,
+		MaxOpenConns: 1,
+	}, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open slashing protection db %s, err=%s", path, err.Error())
+	}
+	if err = journalDB.AutoMigrate(&SignedVote{}); err != nil {
+		return nil, fmt.Errorf("failed to create slashing protection table, err=%s", err.Error())
+	}
+	return &Journal{db: journalDB}, nil
+}
+
+// CheckAndRecord records that pubKey signs eventHash for the event type, channel and sequence. Signing the same event
+// hash again is allowed, signing a different one returns ErrConflictingVote and nothing is recorded.
+func (j *Journal) CheckAndRecord(pubKey []byte, eventType uint32, channelId uint8, sequence uint64, eventHash []byte) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.db.Transaction(func(tx *gorm.DB) error {
+		_, err := checkAndRecord(tx, &SignedVote{
+			PubKey:      hex.EncodeToString(pubKey),
+			EventType:   eventType,
+			ChannelId:   channelId,
+			Sequence:    sequence,
+			EventHash:   hex.EncodeToString(eventHash),
+			CreatedTime: time.Now().Unix(),
+		})
+		return err
+	})
+}
+
+// checkAndRecord saves v if its key has not signed the vote yet and reports whether it was saved.
+func checkAndRecord(tx *gorm.DB, v *SignedVote) (bool, error) {
+	existing := SignedVote{}
+	err := tx.Where("pub_key = ? and event_type = ? and channel_id = ? and sequence = ?",
+		v.PubKey, v.EventType, v.ChannelId, v.Sequence).Take(&existing).Error
+	if err == nil {
+		if existing.EventHash != v.EventHash {
+			return false, fmt.Errorf("%w: event type %d, channel %d and sequence %d were signed with event hash %s, refuse to sign %s",
+				ErrConflictingVote, v.EventType, v.ChannelId, v.Sequence, existing.EventHash, v.EventHash)
+		}
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	return true, tx.Create(v).Error
+}
+
+func (j *Journal) Close() error {
+	sqlDB, err := j.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,60 @@
+package protection
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJournal(t *testing.T) *Journal {
+	j, err := OpenJournal(filepath.Join(t.TempDir(), "slashing_protection.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = j.Close() })
+	return j
+}
+
+func TestJournalRefusesConflictingVote(t *testing.T) {
+	j := newTestJournal(t)
+	pubKey := []byte{1, 2, 3}
+
+	require.NoError(t, j.CheckAndRecord(pubKey, 0, 0, 1, []byte{0xa}))
+	// signing the same vote again is safe
+	require.NoError(t, j.CheckAndRecord(pubKey, 0, 0, 1, []byte{0xa}))
+
+	err := j.CheckAndRecord(pubKey, 0, 0, 1, []byte{0xb})
+	require.True(t, errors.Is(err, ErrConflictingVote))
+
+	// other event types, channels, sequences and keys are independent
+	require.NoError(t, j.CheckAndRecord(pubKey, 1, 0, 1, []byte{0xb}))
+	require.NoError(t, j.CheckAndRecord(pubKey, 0, 1, 1, []byte{0xb}))
+	require.NoError(t, j.CheckAndRecord(pubKey, 0, 0, 2, []byte{0xb}))
+	require.NoError(t, j.CheckAndRecord([]byte{4}, 0, 0, 1, []byte{0xb}))
+}
+
+func TestJournalInterchange(t *testing.T) {
+	src := newTestJournal(t)
+	require.NoError(t, src.CheckAndRecord([]byte{1}, 0, 0, 1, []byte{0xa}))
+	require.NoError(t, src.CheckAndRecord([]byte{1}, 0, 0, 2, []byte{0xb}))
+	require.NoError(t, src.CheckAndRecord([]byte{2}, 1, 10, 1, []byte{0xc}))
+
+	var buf bytes.Buffer
+	require.NoError(t, src.Export(&buf))
+	exported := buf.Bytes()
+
+	dst := newTestJournal(t)
+	require.NoError(t, dst.CheckAndRecord([]byte{1}, 0, 0, 1, []byte{0xa}))
+	imported, err := dst.Import(bytes.NewReader(exported))
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+	require.True(t, errors.Is(dst.CheckAndRecord([]byte{1}, 0, 0, 2, []byte{0xd}), ErrConflictingVote))
+
+	conflicting := newTestJournal(t)
+	require.NoError(t, conflicting.CheckAndRecord([]byte{2}, 1, 10, 1, []byte{0xd}))
+	_, err = conflicting.Import(bytes.NewReader(exported))
+	require.True(t, errors.Is(err, ErrConflictingVote))
+	// nothing is imported when any vote conflicts
+	require.NoError(t, conflicting.CheckAndRecord([]byte{1}, 0, 0, 1, []byte{0xe}))
+}
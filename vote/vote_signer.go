@@ -1,35 +1,65 @@
 package vote
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/0xPolygon/polygon-edge/bls"
 	"github.com/cometbft/cometbft/votepool"
+
+	"github.com/zkMeLabs/mechain-relayer/vote/protection"
 )
 
 type VoteSigner struct {
+	mutex   sync.RWMutex
 	privKey *bls.PrivateKey
 	pubKey  *bls.PublicKey
+	journal *protection.Journal
 }
 
 // var DST = []byte("BLS_SIG_BN254G1_XMD:SHA-256_SVDW_RO_NUL_") //0x416a79f61c64a68f9946f715ec2b7077204a431ad6c623c2b7e464d4b60b0ed6
 
-func NewVoteSigner(pk []byte) *VoteSigner {
-	privKey, err := bls.UnmarshalPrivateKey(pk)
-	if err != nil {
+func NewVoteSigner(pk []byte, journal *protection.Journal) *VoteSigner {
+	signer := &VoteSigner{journal: journal}
+	if err := signer.SetKey(pk); err != nil {
 		panic(err)
 	}
-	pubKey := privKey.PublicKey()
-	return &VoteSigner{
-		privKey: privKey,
-		pubKey:  pubKey,
+	return signer
+}
+
+// SetKey swaps the BLS private key of the signer.
+func (signer *VoteSigner) SetKey(pk []byte) error {
+	privKey, err := bls.UnmarshalPrivateKey(pk)
+	if err != nil {
+		return err
 	}
+	signer.mutex.Lock()
+	defer signer.mutex.Unlock()
+	signer.privKey = privKey
+	signer.pubKey = privKey.PublicKey()
+	return nil
 }
 
-// SignVote signs a vote by relayer's private key
-func (signer *VoteSigner) SignVote(vote *votepool.Vote) {
+// PubKey returns the BLS public key of the votes signed by the signer.
+func (signer *VoteSigner) PubKey() []byte {
+	signer.mutex.RLock()
+	defer signer.mutex.RUnlock()
+	return signer.pubKey.Marshal()
+}
+
+// SignVote signs a vote by relayer's private key. The vote is recorded in the slashing protection journal first, and
+// it is not signed if the relayer has signed a different event hash for the same channel and sequence.
+func (signer *VoteSigner) SignVote(vote *votepool.Vote, channelId uint8, sequence uint64) error {
+	signer.mutex.RLock()
+	defer signer.mutex.RUnlock()
 	vote.PubKey = signer.pubKey.Marshal()
+	if err := signer.journal.CheckAndRecord(vote.PubKey, uint32(vote.EventType), channelId, sequence, vote.EventHash); err != nil {
+		return fmt.Errorf("slashing protection refused to sign vote for channel id %d and sequence %d, err=%w", channelId, sequence, err)
+	}
 	signature, err := signer.privKey.Sign(vote.EventHash[:], votepool.DST)
 	if err != nil {
 		panic(err)
 	}
 	vote.Signature, _ = signature.Marshal()
+	return nil
 }